@@ -0,0 +1,90 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/schapirolab/hip-sl/hipsl"
+)
+
+// Convert is the convert subcommand, which imports the .dat pattern files and
+// .wts weights files of the C++ emergent project into the Go formats of etable
+// and leabra, as given by the args after convert, e.g.:
+//
+//	hip-sl convert Train_pairs.dat hip_trained.wts.gz
+//
+// The weights are converted for the network of the -paradigm and -topo args.
+// It returns the exit code of the program.
+func Convert(args []string) int {
+	cfg := hipsl.DefaultConfig()
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	var out, lays, paradigm string
+	var reshape bool
+	fs.StringVar(&out, "o", "", "file to save the converted file to, for one file only -- by default its name with _go before the extension, e.g., Train_pairs_go.dat")
+	fs.StringVar(&lays, "layers", "", "extra names of C++ layers to map to the layers of the network, as Cpp=Go separated by commas, on top of EC_in=ECin,EC_out=ECout")
+	fs.BoolVar(&reshape, "reshape", false, "if true, reshape .dat files even if their layout is not detected as the C++ one, e.g., for 2D columns")
+	fs.StringVar(&paradigm, "paradigm", cfg.Paradigm.String(), "statistical learning paradigm of the network to convert weights for: Pairs, Community or AssocInf")
+	fs.StringVar(&cfg.Topology, "topo", "", "JSON or TOML file of the topology of the network to convert weights for, instead of the default")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s convert [flags] files.dat|files.wts[.gz]...\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 || (out != "" && fs.NArg() > 1) {
+		fs.Usage()
+		return 2
+	}
+	lnames := make(map[string]string)
+	for cnm, gnm := range hipsl.CppLayerNames {
+		lnames[cnm] = gnm
+	}
+	for _, lm := range strings.Split(lays, ",") {
+		if lm = strings.TrimSpace(lm); lm == "" {
+			continue
+		}
+		nms := strings.Split(lm, "=")
+		if len(nms) != 2 {
+			log.Printf("convert: -layers needs Cpp=Go names, not: %v\n", lm)
+			return 2
+		}
+		lnames[strings.TrimSpace(nms[0])] = strings.TrimSpace(nms[1])
+	}
+	if err := cfg.Paradigm.FromString(paradigm); err != nil {
+		log.Println(err)
+		return 2
+	}
+
+	var ss *hipsl.Sim // network to convert weights for, made for the first weights file
+	code := 0
+	for _, fnm := range fs.Args() {
+		onm := out
+		if onm == "" {
+			onm = hipsl.ConvertFileName(fnm)
+		}
+		var err error
+		if ext := strings.TrimSuffix(fnm, ".gz"); filepath.Ext(ext) == ".wts" {
+			if ss == nil {
+				if ss, err = hipsl.New(cfg); err != nil {
+					log.Println(err)
+					return 1
+				}
+			}
+			err = ss.ConvertWts(fnm, onm, lnames, os.Stdout)
+		} else {
+			err = hipsl.ConvertPats(fnm, onm, reshape, os.Stdout)
+		}
+		if err != nil {
+			log.Println(err)
+			code = 1
+		}
+	}
+	return code
+}
@@ -0,0 +1,627 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/emer/emergent/netview"
+	"github.com/emer/etable/eplot"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etview"
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+	"github.com/schapirolab/hip-sl/hipsl"
+)
+
+// Gui is the GoGi gui of a Sim: its window, network view and plots of its logs,
+// which it updates as a LogObserver and through the Hooks of the Sim
+type Gui struct {
+	Sim        *hipsl.Sim                    `desc:"the Sim shown in the gui"`
+	Win        *gi.Window                    `desc:"main GUI window"`
+	NetView    *netview.NetView              `desc:"the network viewer"`
+	ToolBar    *gi.ToolBar                   `desc:"the master toolbar"`
+	TrnTrlPlot *eplot.Plot2D                 `desc:"the training trial plot"`
+	TrnEpcPlot *eplot.Plot2D                 `desc:"the training epoch plot"`
+	TstEpcPlot *eplot.Plot2D                 `desc:"the testing epoch plot"`
+	TstTrlPlot *eplot.Plot2D                 `desc:"the test-trial plot"`
+	TstCycPlot *eplot.Plot2D                 `desc:"the test-cycle plot"`
+	RunPlot    *eplot.Plot2D                 `desc:"the run plot"`
+	RSAPlot    *eplot.Plot2D                 `desc:"the RSA plot"`
+	ReplayPlot *eplot.Plot2D                 `desc:"the replay plot"`
+	WtEpcPlot  *eplot.Plot2D                 `desc:"the weight epoch plot"`
+	RSAGrids   map[string]*etview.SimMatGrid `desc:"the RSA similarity matrix views, by layer"`
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////
+// 		Gui
+
+// ConfigGui configures the GoGi gui interface for the Sim, and sets its Hooks
+// to update the gui
+func (gu *Gui) ConfigGui() *gi.Window {
+	ss := gu.Sim
+	width := 1600
+	height := 1200
+
+	gi.SetAppName("hip")
+	gi.SetAppAbout(`This demonstrates a basic Hippocampus model in Leabra. See <a href="https://github.com/emer/emergent">emergent on GitHub</a>.</p>`)
+
+	win := gi.NewMainWindow("hip", "Hippocampus AB-AC", width, height)
+	gu.Win = win
+
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+
+	mfr := win.SetMainFrame()
+
+	tbar := gi.AddNewToolBar(mfr, "tbar")
+	tbar.SetStretchMaxWidth()
+	gu.ToolBar = tbar
+
+	split := gi.AddNewSplitView(mfr, "split")
+	split.Dim = mat32.X
+	split.SetStretchMax()
+
+	sv := giv.AddNewStructView(split, "sv")
+	sv.SetStruct(ss)
+
+	tv := gi.AddNewTabView(split, "tv")
+
+	nv := tv.AddNewTab(netview.KiT_NetView, "NetView").(*netview.NetView)
+	nv.Var = "Act"
+	// nv.Params.ColorMap = "Jet" // default is ColdHot
+	// which fares pretty well in terms of discussion here:
+	// https://matplotlib.org/tutorials/colors/colormaps.html
+	nv.SetNet(ss.Net)
+	gu.NetView = nv
+	nv.ViewDefaults()
+
+	plt := tv.AddNewTab(eplot.KiT_Plot2D, "TrnTrlPlot").(*eplot.Plot2D)
+	gu.TrnTrlPlot = gu.ConfigTrnTrlPlot(plt, ss.TrnTrlLog)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "TrnEpcPlot").(*eplot.Plot2D)
+	gu.TrnEpcPlot = gu.ConfigTrnEpcPlot(plt, ss.TrnEpcLog)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "TstTrlPlot").(*eplot.Plot2D)
+	gu.TstTrlPlot = gu.ConfigTstTrlPlot(plt, ss.TstTrlLog)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "TstEpcPlot").(*eplot.Plot2D)
+	gu.TstEpcPlot = gu.ConfigTstEpcPlot(plt, ss.TstEpcLog)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "TstCycPlot").(*eplot.Plot2D)
+	gu.TstCycPlot = gu.ConfigTstCycPlot(plt, ss.TstCycLog)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "RunPlot").(*eplot.Plot2D)
+	gu.RunPlot = gu.ConfigRunPlot(plt, ss.RunLog)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "RSAPlot").(*eplot.Plot2D)
+	gu.RSAPlot = gu.ConfigRSAPlot(plt, ss.RSALog)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "ReplayPlot").(*eplot.Plot2D)
+	gu.ReplayPlot = gu.ConfigReplayPlot(plt, ss.ReplayLog)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "WtEpcPlot").(*eplot.Plot2D)
+	gu.WtEpcPlot = gu.ConfigWtEpcPlot(plt, ss.WtEpcLog)
+
+	rl := tv.AddNewTab(gi.KiT_Layout, "RSA").(*gi.Layout)
+	rl.Lay = gi.LayoutHoriz
+	rl.SetStretchMax()
+	gu.RSAGrids = make(map[string]*etview.SimMatGrid)
+	for _, lnm := range ss.RSA.Layers {
+		lv := gi.AddNewLayout(rl, lnm, gi.LayoutVert)
+		gi.AddNewLabel(lv, "lbl", lnm)
+		gu.RSAGrids[lnm] = etview.AddNewSimMatGrid(lv, "simat", ss.RSA.SimMats[lnm])
+	}
+
+	split.SetSplits(.2, .8)
+
+	tbar.AddAction(gi.ActOpts{Label: "Init", Icon: "update", Tooltip: "Initialize everything including network weights, and start over.  Also applies current params.", UpdateFunc: func(act *gi.Action) {
+		act.SetActiveStateUpdt(!ss.IsRunning)
+	}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		ss.Init()
+		vp.SetNeedsFullRender()
+	})
+
+	tbar.AddAction(gi.ActOpts{Label: "Train", Icon: "run", Tooltip: "Starts the network training, picking up from wherever it may have left off.  If not stopped, training will complete the specified number of Runs through the full number of Epochs of training, with testing automatically occuring at the specified interval.",
+		UpdateFunc: func(act *gi.Action) {
+			act.SetActiveStateUpdt(!ss.IsRunning)
+		}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if !ss.IsRunning {
+			ss.IsRunning = true
+			tbar.UpdateActions()
+			// ss.Train()
+			go ss.Train()
+		}
+	})
+
+	tbar.AddAction(gi.ActOpts{Label: "Stop", Icon: "stop", Tooltip: "Interrupts running.  Hitting Train again will pick back up where it left off.", UpdateFunc: func(act *gi.Action) {
+		act.SetActiveStateUpdt(ss.IsRunning)
+	}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		ss.Stop()
+	})
+
+	tbar.AddAction(gi.ActOpts{Label: "Resume", Icon: "file-open", Tooltip: "Restores a checkpoint saved every CkptInterval epochs, including its params, weights, counters, random state and logs -- hit Train to continue from it.", UpdateFunc: func(act *gi.Action) {
+		act.SetActiveStateUpdt(!ss.IsRunning)
+	}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		giv.CallMethod(ss, "Resume", vp)
+	})
+
+	tbar.AddAction(gi.ActOpts{Label: "Step Trial", Icon: "step-fwd", Tooltip: "Advances one training trial at a time.", UpdateFunc: func(act *gi.Action) {
+		act.SetActiveStateUpdt(!ss.IsRunning)
+	}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if !ss.IsRunning {
+			ss.IsRunning = true
+			ss.TrainTrial()
+			ss.IsRunning = false
+			vp.SetNeedsFullRender()
+		}
+	})
+
+	tbar.AddAction(gi.ActOpts{Label: "Step Epoch", Icon: "fast-fwd", Tooltip: "Advances one epoch (complete set of training patterns) at a time.", UpdateFunc: func(act *gi.Action) {
+		act.SetActiveStateUpdt(!ss.IsRunning)
+	}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if !ss.IsRunning {
+			ss.IsRunning = true
+			tbar.UpdateActions()
+			go ss.TrainEpoch()
+		}
+	})
+
+	tbar.AddAction(gi.ActOpts{Label: "Step Run", Icon: "fast-fwd", Tooltip: "Advances one full training Run at a time.", UpdateFunc: func(act *gi.Action) {
+		act.SetActiveStateUpdt(!ss.IsRunning)
+	}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if !ss.IsRunning {
+			ss.IsRunning = true
+			tbar.UpdateActions()
+			go ss.TrainRun()
+		}
+	})
+
+	tbar.AddSeparator("test")
+
+	tbar.AddAction(gi.ActOpts{Label: "Test Trial", Icon: "step-fwd", Tooltip: "Runs the next testing trial.", UpdateFunc: func(act *gi.Action) {
+		act.SetActiveStateUpdt(!ss.IsRunning)
+	}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if !ss.IsRunning {
+			ss.IsRunning = true
+			ss.TestTrial(false) // don't return on trial -- wrap
+			ss.IsRunning = false
+			vp.SetNeedsFullRender()
+		}
+	})
+
+	tbar.AddAction(gi.ActOpts{Label: "Test Item", Icon: "step-fwd", Tooltip: "Prompts for a specific input pattern name to run, and runs it in testing mode.", UpdateFunc: func(act *gi.Action) {
+		act.SetActiveStateUpdt(!ss.IsRunning)
+	}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		gi.StringPromptDialog(vp, "", "Test Item",
+			gi.DlgOpts{Title: "Test Item", Prompt: "Enter the Name of a given input pattern to test (case insensitive, contains given string."},
+			win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				dlg := send.(*gi.Dialog)
+				if sig == int64(gi.DialogAccepted) {
+					val := gi.StringPromptDialogValue(dlg)
+					idxs := ss.TestEnv.Table.RowsByString("Name", val, etable.Contains, etable.IgnoreCase)
+					if len(idxs) == 0 {
+						gi.PromptDialog(nil, gi.DlgOpts{Title: "Name Not Found", Prompt: "No patterns found containing: " + val}, gi.AddOk, gi.NoCancel, nil, nil)
+					} else {
+						if !ss.IsRunning {
+							ss.IsRunning = true
+							fmt.Printf("testing index: %v\n", idxs[0])
+							ss.TestItem(idxs[0])
+							ss.IsRunning = false
+							vp.SetNeedsFullRender()
+						}
+					}
+				}
+			})
+	})
+
+	tbar.AddAction(gi.ActOpts{Label: "Test All", Icon: "fast-fwd", Tooltip: "Tests all of the testing trials.", UpdateFunc: func(act *gi.Action) {
+		act.SetActiveStateUpdt(!ss.IsRunning)
+	}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if !ss.IsRunning {
+			ss.IsRunning = true
+			tbar.UpdateActions()
+			go ss.RunTestAll()
+		}
+	})
+
+	tbar.AddAction(gi.ActOpts{Label: "Replay", Icon: "fast-fwd", Tooltip: "Runs a phase of Replay trials, as run between training epochs when Replay is On, logging the reactivations in the ReplayLog.", UpdateFunc: func(act *gi.Action) {
+		act.SetActiveStateUpdt(!ss.IsRunning)
+	}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if !ss.IsRunning {
+			ss.IsRunning = true
+			tbar.UpdateActions()
+			go ss.RunReplay()
+		}
+	})
+
+	tbar.AddAction(gi.ActOpts{Label: "Paradigm", Icon: "gear", Tooltip: "select the statistical learning paradigm to train and test on: Pairs, Community or AssocInf -- reconfigures the network and logs."}, win.This(),
+		func(recv, send ki.Ki, sig int64, data interface{}) {
+			giv.CallMethod(ss, "SetParadigm", vp)
+		})
+
+	tbar.AddSeparator("log")
+
+	tbar.AddAction(gi.ActOpts{Label: "Reset RunLog", Icon: "reset", Tooltip: "Reset the accumulated log of all Runs, which are tagged with the ParamSet used"}, win.This(),
+		func(recv, send ki.Ki, sig int64, data interface{}) {
+			ss.RunLog.SetNumRows(0)
+			gu.RunPlot.Update()
+		})
+
+	tbar.AddSeparator("misc")
+
+	tbar.AddAction(gi.ActOpts{Label: "New Seed", Icon: "new", Tooltip: "Generate a new initial random seed to get different results.  By default, Init re-establishes the same initial seed every time."}, win.This(),
+		func(recv, send ki.Ki, sig int64, data interface{}) {
+			ss.NewRndSeed()
+		})
+
+	tbar.AddAction(gi.ActOpts{Label: "README", Icon: "file-markdown", Tooltip: "Opens your browser on the README file that contains instructions for how to run this model."}, win.This(),
+		func(recv, send ki.Ki, sig int64, data interface{}) {
+			gi.OpenURL("https://github.com/emer/leabra/blob/master/examples/ra25/README.md")
+		})
+
+	vp.UpdateEndNoSig(updt)
+
+	// main menu
+	appnm := gi.AppName()
+	mmen := win.MainMenu
+	mmen.ConfigMenus([]string{appnm, "File", "Edit", "Window"})
+
+	amen := win.MainMenu.ChildByName(appnm, 0).(*gi.Action)
+	amen.Menu.AddAppMenu(win)
+
+	emen := win.MainMenu.ChildByName("Edit", 1).(*gi.Action)
+	emen.Menu.AddCopyCutPaste(win)
+
+	// note: Command in shortcuts is automatically translated into Control for
+	// Linux, Windows or Meta for MacOS
+	// fmen := win.MainMenu.ChildByName("File", 0).(*gi.Action)
+	// fmen.Menu.AddAction(gi.ActOpts{Label: "Open", Shortcut: "Command+O"},
+	// 	win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+	// 		FileViewOpenSVG(vp)
+	// 	})
+	// fmen.Menu.AddSeparator("csep")
+	// fmen.Menu.AddAction(gi.ActOpts{Label: "Close Window", Shortcut: "Command+W"},
+	// 	win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+	// 		win.Close()
+	// 	})
+
+	inQuitPrompt := false
+	gi.SetQuitReqFunc(func() {
+		if inQuitPrompt {
+			return
+		}
+		inQuitPrompt = true
+		gi.PromptDialog(vp, gi.DlgOpts{Title: "Really Quit?",
+			Prompt: "Are you <i>sure</i> you want to quit and lose any unsaved params, weights, logs, etc?"}, gi.AddOk, gi.AddCancel,
+			win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				if sig == int64(gi.DialogAccepted) {
+					gi.Quit()
+				} else {
+					inQuitPrompt = false
+				}
+			})
+	})
+
+	// gi.SetQuitCleanFunc(func() {
+	// 	fmt.Printf("Doing final Quit cleanup here..\n")
+	// })
+
+	inClosePrompt := false
+	win.SetCloseReqFunc(func(w *gi.Window) {
+		if inClosePrompt {
+			return
+		}
+		inClosePrompt = true
+		gi.PromptDialog(vp, gi.DlgOpts{Title: "Really Close Window?",
+			Prompt: "Are you <i>sure</i> you want to close the window?  This will Quit the App as well, losing all unsaved params, weights, logs, etc"}, gi.AddOk, gi.AddCancel,
+			win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				if sig == int64(gi.DialogAccepted) {
+					gi.Quit()
+				} else {
+					inClosePrompt = false
+				}
+			})
+	})
+
+	win.SetCloseCleanFunc(func(w *gi.Window) {
+		go gi.Quit() // once main window is closed, quit
+	})
+
+	gu.SetHooks()
+
+	win.MainMenuUpdated()
+	return win
+}
+
+// SetHooks subscribes the plots of the gui to the logs of the Sim, and sets its
+// Hooks to update the network view and toolbar
+func (gu *Gui) SetHooks() {
+	ss := gu.Sim
+	ss.Observe(&LogPlot{Plot: gu.TrnTrlPlot}, ss.TrnTrlLog)
+	ss.Observe(&LogPlot{Plot: gu.TrnEpcPlot}, ss.TrnEpcLog)
+	ss.Observe(&LogPlot{Plot: gu.TstTrlPlot}, ss.TstTrlLog)
+	ss.Observe(&LogPlot{Plot: gu.TstEpcPlot}, ss.TstEpcLog)
+	ss.Observe(&LogPlot{Plot: gu.TstCycPlot, Every: 10}, ss.TstCycLog) // too slow to do every cyc
+	ss.Observe(&LogPlot{Plot: gu.RSAPlot}, ss.RSALog)
+	ss.Observe(&LogPlot{Plot: gu.ReplayPlot}, ss.ReplayLog)
+	ss.Observe(&LogPlot{Plot: gu.WtEpcPlot}, ss.WtEpcLog)
+	ss.Observe(&LogPlot{Plot: gu.RunPlot}, ss.RunLog)
+	ss.Observe(hipsl.LogObserverFunc(func(dt *etable.Table, row int) {
+		for lnm, sg := range gu.RSAGrids {
+			sg.SetSimMat(ss.RSA.SimMats[lnm])
+		}
+	}), ss.RSALog)
+	ss.Hooks.ViewUpdated = func(train bool) {
+		if gu.NetView != nil && gu.NetView.IsVisible() {
+			gu.NetView.Record(ss.Counters(train), -1)
+			// note: essential to use Go version of update when called from another goroutine
+			gu.NetView.GoUpdate() // note: using counters is significantly slower..
+		}
+	}
+	ss.Hooks.Reconfigured = func() {
+		gu.NetView.SetNet(ss.Net)
+		gu.ConfigTstTrlPlot(gu.TstTrlPlot, ss.TstTrlLog)
+		gu.ConfigTstEpcPlot(gu.TstEpcPlot, ss.TstEpcLog)
+		gu.ConfigRunPlot(gu.RunPlot, ss.RunLog)
+		gu.ConfigWtEpcPlot(gu.WtEpcPlot, ss.WtEpcLog)
+	}
+	ss.Hooks.Stopped = func() {
+		vp := gu.Win.WinViewport2D()
+		if gu.ToolBar != nil {
+			gu.ToolBar.UpdateActions()
+		}
+		vp.SetNeedsFullRender()
+	}
+}
+
+func (gu *Gui) ConfigTrnTrlPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Hippocampus Train Trial Plot"
+	plt.Params.XAxisCol = "Trial"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Epoch", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Trial", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("TrialName", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("SSE", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("AvgSSE", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("CosDiff", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+
+	plt.SetColParams("Mem", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("TrgOnWasOff", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("TrgOffWasOn", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+
+	return plt
+}
+
+func (gu *Gui) ConfigTrnEpcPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	ss := gu.Sim
+	plt.Params.Title = "Hippocampus Epoch Plot"
+	plt.Params.XAxisCol = "Epoch"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Epoch", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("SSE", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("AvgSSE", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("PctErr", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("PctCor", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("CosDiff", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+
+	plt.SetColParams("Mem", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)         // default plot
+	plt.SetColParams("TrgOnWasOff", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1) // default plot
+	plt.SetColParams("TrgOffWasOn", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1) // default plot
+
+	for _, lnm := range ss.LayStatNms {
+		plt.SetColParams(lnm+" ActAvg", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 0.5)
+	}
+	return plt
+}
+
+func (gu *Gui) ConfigTstTrlPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	ss := gu.Sim
+	plt.Params.Title = "Hippocampus Test Trial Plot"
+	plt.Params.XAxisCol = "TrialName"
+	plt.Params.Type = eplot.Bar
+	plt.SetTable(dt) // this sets defaults so set params after
+	plt.Params.BarWidth = 5
+	plt.Params.XAxisRot = 45
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Epoch", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("TestNm", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Trial", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("TrialName", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Group", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("SSE", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("AvgSSE", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("CosDiff", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+
+	plt.SetColParams("Mem", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("TrgOnWasOff", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("TrgOffWasOn", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("ProdSelf", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("ProdPartner", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("ProdOther", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("ProdProbs", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("Match", eplot.Off, eplot.FloatMin, 0, eplot.FixMax, 1)
+
+	for _, lnm := range ss.LayStatNms {
+		plt.SetColParams(lnm+" ActM.Avg", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 0.5)
+		plt.SetColParams(lnm+" ActM", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	}
+
+	// plt.SetColParams("InAct", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	// plt.SetColParams("OutActM", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	// plt.SetColParams("OutActP", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	return plt
+}
+
+func (gu *Gui) ConfigTstEpcPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	ss := gu.Sim
+	plt.Params.Title = "Hippocampus Testing Epoch Plot"
+	plt.Params.XAxisCol = "Epoch"
+	plt.SetTable(dt) // this sets defaults so set params after
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Epoch", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("PerTrlMSec", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("SSE", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("AvgSSE", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("PctErr", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("PctCor", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("CosDiff", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+
+	for _, tn := range ss.TstNms {
+		for _, ts := range ss.TstStatNms {
+			if ts == "Mem" || ts == "ProdPartner" {
+				plt.SetColParams(tn+" "+ts, eplot.On, eplot.FixMin, 0, eplot.FixMax, 1) // default plot
+			} else {
+				plt.SetColParams(tn+" "+ts, eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1) // default plot
+			}
+		}
+	}
+	if ss.Paradigm == hipsl.Community {
+		for _, lnm := range ss.LayStatNms {
+			plt.SetColParams(lnm+" WithinComm", eplot.On, eplot.FixMin, -1, eplot.FixMax, 1)
+			plt.SetColParams(lnm+" BetweenComm", eplot.On, eplot.FixMin, -1, eplot.FixMax, 1)
+		}
+	}
+	if ss.Paradigm == hipsl.AssocInf {
+		plt.SetColParams("Infer", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	}
+	if ss.RecogOn {
+		for _, cl := range hipsl.RecogCols() {
+			if strings.Contains(cl, "DPrime") {
+				plt.SetColParams(cl, eplot.Off, eplot.FloatMin, 0, eplot.FloatMax, 0)
+			} else {
+				plt.SetColParams(cl, cl == "Recog Hits" || cl == "Recog FA", eplot.FixMin, 0, eplot.FixMax, 1)
+			}
+		}
+	}
+	return plt
+}
+
+func (gu *Gui) ConfigRSAPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	ss := gu.Sim
+	plt.Params.Title = "Hippocampus RSA Plot"
+	plt.Params.XAxisCol = "Epoch"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Epoch", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	for _, lnm := range ss.RSA.Layers {
+		plt.SetColParams(lnm+" Within", eplot.On, eplot.FixMin, -1, eplot.FixMax, 1)
+		plt.SetColParams(lnm+" Across", eplot.On, eplot.FixMin, -1, eplot.FixMax, 1)
+	}
+	return plt
+}
+
+func (gu *Gui) ConfigTstCycPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	ss := gu.Sim
+	plt.Params.Title = "Hippocampus Test Cycle Plot"
+	plt.Params.XAxisCol = "Cycle"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Cycle", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	for _, lnm := range ss.LayStatNms {
+		plt.SetColParams(lnm+" Ge.Avg", eplot.On, eplot.FixMin, 0, eplot.FixMax, .5)
+		plt.SetColParams(lnm+" Act.Avg", eplot.On, eplot.FixMin, 0, eplot.FixMax, .5)
+	}
+	return plt
+}
+
+func (gu *Gui) ConfigRunPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	ss := gu.Sim
+	plt.Params.Title = "Hippocampus Run Plot"
+	plt.Params.XAxisCol = "Run"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("NEpochs", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("FirstZero", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("SSE", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("AvgSSE", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("PctErr", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("PctCor", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("CosDiff", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+
+	for _, tn := range ss.TstNms {
+		for _, ts := range ss.TstStatNms {
+			if ts == "Mem" || ts == "ProdPartner" {
+				plt.SetColParams(tn+" "+ts, eplot.On, eplot.FixMin, 0, eplot.FixMax, 1) // default plot
+			} else {
+				plt.SetColParams(tn+" "+ts, eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+			}
+		}
+	}
+	if ss.Paradigm == hipsl.AssocInf {
+		plt.SetColParams("Infer", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	}
+	if ss.RecogOn {
+		for _, cl := range hipsl.RecogCols() {
+			if strings.Contains(cl, "DPrime") {
+				plt.SetColParams(cl, eplot.Off, eplot.FloatMin, 0, eplot.FloatMax, 0)
+			} else {
+				plt.SetColParams(cl, cl == "Recog Hits" || cl == "Recog FA", eplot.FixMin, 0, eplot.FixMax, 1)
+			}
+		}
+	}
+	return plt
+}
+
+func (gu *Gui) ConfigReplayPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	ss := gu.Sim
+	plt.Params.Title = "Hippocampus Replay Plot"
+	plt.Params.XAxisCol = "Epoch"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Epoch", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Trial", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	for _, lnm := range ss.Replay.Layers {
+		plt.SetColParams(lnm+" "+ss.RSA.Var+".Avg", eplot.On, eplot.FixMin, 0, eplot.FixMax, .5)
+		plt.SetColParams(lnm+" Sim", eplot.On, eplot.FixMin, -1, eplot.FixMax, 1)
+		plt.SetColParams(lnm+" "+ss.RSA.Var, eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	}
+	return plt
+}
+
+func (gu *Gui) ConfigWtEpcPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Hippocampus Weight Epoch Plot"
+	plt.Params.XAxisCol = "Epoch"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Epoch", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	for _, pj := range gu.Sim.WtSnapPrjns() {
+		pnm := pj.Name()
+		plt.SetColParams(pnm+" DWt", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+		plt.SetColParams(pnm+" Wt", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+		plt.SetColParams(pnm+" Within", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+		plt.SetColParams(pnm+" Across", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+		plt.SetColParams(pnm+" Hist", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	}
+	return plt
+}
+
+// LogPlot is a LogObserver that updates the plot of a log as its rows are logged
+type LogPlot struct {
+	Plot  *eplot.Plot2D `desc:"the plot of the log"`
+	Every int           `desc:"if > 1, only update at every this many rows, e.g., for cycles"`
+}
+
+// LogRow updates the plot
+func (lp *LogPlot) LogRow(dt *etable.Table, row int) {
+	if lp.Every > 1 && row >= 0 && row%lp.Every != 0 {
+		return
+	}
+	// note: essential to use Go version of update when called from another goroutine
+	lp.Plot.GoUpdate()
+}
@@ -0,0 +1,284 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// ActRec records unit activity of a configurable set of layers and variables, at
+// given cycles and / or at the end of given quarters of the alpha cycle, during
+// training and / or testing trials.  Each recorded state is one row of a tab-separated
+// file, which is opened once per run with headers named from the layer sizes of the
+// network: Layer_Var_i for unit i.  If Arrow, each trial is instead one row of an
+// Arrow IPC file, with a float32 tensor column per layer and variable, "Layer Var",
+// of the values of its units at each recorded state of the trial, in the order of
+// the Cycles then the Quarters -- NaN if a state was not reached.
+type ActRec struct {
+	On        bool          `desc:"whether to record activity"`
+	Layers    []string      `desc:"names of the layers to record"`
+	Vars      []string      `desc:"unit variables to record for each layer, e.g., Act, Ge, ActM, ActP"`
+	Cycles    []int         `desc:"cycles within the alpha cycle (0-99) at which to record"`
+	Quarters  []int         `desc:"quarters (0-3) at the end of which to record, after QuarterFinal, e.g., for ActM after quarter 2"`
+	Train     bool          `desc:"record during training trials"`
+	Test      bool          `desc:"record during testing trials"`
+	Dir       string        `desc:"directory to write the activity files to -- created if it does not exist"`
+	Arrow     bool          `desc:"write an Arrow IPC file per run with one row per trial, and a tensor column per layer and variable of its units at each recorded state, instead of a tab-separated file with one row per state"`
+	File      *os.File      `view:"-" desc:"current activity file"`
+	Writer    *csv.Writer   `view:"-" desc:"writer for the current activity file"`
+	Vals      []float32     `view:"-" desc:"temp slice for holding unit values"`
+	Trial     *etable.Table `view:"-" desc:"the states of the current trial recorded if Arrow, as the one row of the current Arrow file"`
+	ArrowFile *ArrowLogFile `view:"-" desc:"current Arrow activity file"`
+	TrialRec  bool          `view:"-" desc:"true if a state of the current trial was recorded into the Trial"`
+}
+
+// Defaults records Act of all the layers of the hippocampus at cycles 19 and 99 of
+// each test trial, into the acts directory
+func (ar *ActRec) Defaults() {
+	ar.Layers = []string{"ECin", "ECout", "DG", "CA3", "CA1"}
+	ar.Vars = []string{"Act"}
+	ar.Cycles = []int{19, 99}
+	ar.Test = true
+	ar.Dir = "acts"
+}
+
+// Open creates the file of given name in Dir, closing any current one, and writes
+// the headers: the names of the given counter columns, Cycle, Qtr, and the units
+// of each Var of each of the Layers of net.  If Arrow, it is an Arrow IPC file
+// with the counter columns and the tensor columns of the states of the trial.
+func (ar *ActRec) Open(fname string, net emer.Network, ctrs etable.Schema) error {
+	ar.Close()
+	if ar.Dir != "" {
+		if err := os.MkdirAll(ar.Dir, os.ModePerm); err != nil {
+			return err
+		}
+	}
+	if ar.Arrow {
+		return ar.OpenArrow(filepath.Join(ar.Dir, fname), net, ctrs)
+	}
+	f, err := os.Create(filepath.Join(ar.Dir, fname))
+	if err != nil {
+		return err
+	}
+	ar.File = f
+	ar.Writer = csv.NewWriter(f)
+	ar.Writer.Comma = '\t'
+	var hdrs []string
+	for _, cl := range ctrs {
+		hdrs = append(hdrs, cl.Name)
+	}
+	hdrs = append(hdrs, "Cycle", "Qtr")
+	for _, lnm := range ar.Layers {
+		ly, err := net.LayerByNameTry(lnm)
+		if err != nil {
+			return err
+		}
+		for _, vnm := range ar.Vars {
+			if _, err := ly.UnitVarIdx(vnm); err != nil {
+				return err
+			}
+			for i := 0; i < ly.Shape().Len(); i++ {
+				hdrs = append(hdrs, fmt.Sprintf("%s_%s_%d", lnm, vnm, i))
+			}
+		}
+	}
+	return ar.Writer.Write(hdrs)
+}
+
+// OpenArrow creates the Arrow IPC file of given name, with the given counter
+// columns and a tensor column of the NStates states by the units of each Var of
+// each of the Layers of net, whose states are named in the states metadata
+func (ar *ActRec) OpenArrow(fname string, net emer.Network, ctrs etable.Schema) error {
+	sch := append(etable.Schema{}, ctrs...)
+	for _, lnm := range ar.Layers {
+		ly, err := net.LayerByNameTry(lnm)
+		if err != nil {
+			return err
+		}
+		lshp := ly.Shape().Shapes()
+		lnms := ly.Shape().DimNames()
+		if len(lnms) != len(lshp) {
+			lnms = []string{"Y", "X"}
+			if len(lshp) == 4 {
+				lnms = []string{"PoolY", "PoolX", "Y", "X"}
+			}
+		}
+		shp := append([]int{ar.NStates()}, lshp...)
+		dnms := append([]string{"State"}, lnms...)
+		for _, vnm := range ar.Vars {
+			if _, err := ly.UnitVarIdx(vnm); err != nil {
+				return err
+			}
+			sch = append(sch, etable.Column{lnm + " " + vnm, etensor.FLOAT32, shp, dnms})
+		}
+	}
+	ar.Trial = &etable.Table{}
+	ar.Trial.SetFromSchema(sch, 1)
+	ar.Trial.SetMetaData("name", "ActRec")
+	ar.Trial.SetMetaData("states", strings.Join(ar.StateNames(), ","))
+	ar.ResetTrial()
+	af, err := CreateArrowLogFile(fname, ar.Trial, 0)
+	if err != nil {
+		return err
+	}
+	ar.ArrowFile = af
+	return nil
+}
+
+// NStates returns the number of states recorded per trial: the Cycles and Quarters
+func (ar *ActRec) NStates() int {
+	return len(ar.Cycles) + len(ar.Quarters)
+}
+
+// StateNames returns the names of the states recorded per trial, e.g., cyc19 or qtr2
+func (ar *ActRec) StateNames() []string {
+	var nms []string
+	for _, cyc := range ar.Cycles {
+		nms = append(nms, fmt.Sprintf("cyc%d", cyc))
+	}
+	for _, qtr := range ar.Quarters {
+		nms = append(nms, fmt.Sprintf("qtr%d", qtr))
+	}
+	return nms
+}
+
+// ResetTrial sets all the states of the Trial to NaN, for the next trial
+func (ar *ActRec) ResetTrial() {
+	for _, cl := range ar.Trial.Cols {
+		if ft, ok := cl.(*etensor.Float32); ok {
+			for i := range ft.Values {
+				ft.Values[i] = float32(math.NaN())
+			}
+		}
+	}
+	ar.TrialRec = false
+}
+
+// EndTrial writes the Trial to the Arrow file, if Arrow and any of its states were
+// recorded -- called at the end of each trial
+func (ar *ActRec) EndTrial() {
+	if ar.ArrowFile == nil || !ar.TrialRec {
+		return
+	}
+	ar.ArrowFile.LogRow(ar.Trial, 0)
+	ar.ResetTrial()
+}
+
+// Close flushes and closes the current file, if open
+func (ar *ActRec) Close() {
+	if ar.ArrowFile != nil {
+		ar.EndTrial()
+		if err := ar.ArrowFile.Close(); err != nil {
+			log.Println(err)
+		}
+		ar.ArrowFile = nil
+		ar.Trial = nil
+	}
+	if ar.File == nil {
+		return
+	}
+	ar.Writer.Flush()
+	ar.File.Close()
+	ar.File = nil
+	ar.Writer = nil
+}
+
+// IsRecording returns true if recording into an open file in given phase of the trial
+func (ar *ActRec) IsRecording(train bool) bool {
+	if !ar.On || (ar.File == nil && ar.ArrowFile == nil) {
+		return false
+	}
+	if train {
+		return ar.Train
+	}
+	return ar.Test
+}
+
+// RecCycle returns true if the state at given cycle is to be recorded
+func (ar *ActRec) RecCycle(train bool, cyc int) bool {
+	return ar.IsRecording(train) && hasInt(ar.Cycles, cyc)
+}
+
+// RecQuarter returns true if the state at the end of given quarter is to be recorded
+func (ar *ActRec) RecQuarter(train bool, qtr int) bool {
+	return ar.IsRecording(train) && hasInt(ar.Quarters, qtr)
+}
+
+// Record writes the current values of the recorded variables of net, after
+// given values of the counter columns and the cycle and quarter -- qtr is -1 for
+// cycle records.  If Arrow, they are recorded into the state of the Trial.
+func (ar *ActRec) Record(net emer.Network, ctrs []string, cyc, qtr int) {
+	if ar.ArrowFile != nil {
+		ar.RecordState(net, ctrs, cyc, qtr)
+		return
+	}
+	row := append([]string{}, ctrs...)
+	row = append(row, strconv.Itoa(cyc), strconv.Itoa(qtr))
+	for _, lnm := range ar.Layers {
+		ly := net.LayerByName(lnm)
+		for _, vnm := range ar.Vars {
+			ar.Vals = ar.Vals[:0] // UnitVals only grows it
+			ly.UnitVals(&ar.Vals, vnm)
+			for _, v := range ar.Vals {
+				row = append(row, strconv.FormatFloat(float64(v), 'g', -1, 32))
+			}
+		}
+	}
+	ar.Writer.Write(row)
+}
+
+// RecordState records the current values of the recorded variables of net into
+// the state of the Trial of given cycle or quarter, with given values of its
+// counter columns
+func (ar *ActRec) RecordState(net emer.Network, ctrs []string, cyc, qtr int) {
+	si := indexInt(ar.Cycles, cyc)
+	if qtr >= 0 {
+		si = len(ar.Cycles) + indexInt(ar.Quarters, qtr)
+	}
+	for ci, ctr := range ctrs {
+		ar.Trial.SetCellStringIdx(ci, 0, ctr)
+	}
+	for _, lnm := range ar.Layers {
+		ly := net.LayerByName(lnm)
+		for _, vnm := range ar.Vars {
+			ar.Vals = ar.Vals[:0] // UnitVals only grows it
+			ly.UnitVals(&ar.Vals, vnm)
+			cl := ar.Trial.ColByName(lnm + " " + vnm).(*etensor.Float32)
+			copy(cl.Values[si*len(ar.Vals):], ar.Vals)
+		}
+	}
+	ar.TrialRec = true
+}
+
+// indexInt returns the index of given value in the list, or -1 if not in it
+func indexInt(vals []int, val int) int {
+	for i, v := range vals {
+		if v == val {
+			return i
+		}
+	}
+	return -1
+}
+
+// hasInt returns true if given value is in the list
+func hasInt(vals []int, val int) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,379 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// ArrowBatch is the default number of rows of the record batches of an ArrowLogFile
+const ArrowBatch = 1000
+
+// ArrowType returns the Arrow data type of the values of given etensor type
+func ArrowType(typ etensor.Type) (arrow.DataType, error) {
+	switch typ {
+	case etensor.BOOL:
+		return arrow.FixedWidthTypes.Boolean, nil
+	case etensor.UINT8:
+		return arrow.PrimitiveTypes.Uint8, nil
+	case etensor.INT8:
+		return arrow.PrimitiveTypes.Int8, nil
+	case etensor.UINT16:
+		return arrow.PrimitiveTypes.Uint16, nil
+	case etensor.INT16:
+		return arrow.PrimitiveTypes.Int16, nil
+	case etensor.UINT32:
+		return arrow.PrimitiveTypes.Uint32, nil
+	case etensor.INT32:
+		return arrow.PrimitiveTypes.Int32, nil
+	case etensor.UINT64:
+		return arrow.PrimitiveTypes.Uint64, nil
+	case etensor.INT64:
+		return arrow.PrimitiveTypes.Int64, nil
+	case etensor.FLOAT32:
+		return arrow.PrimitiveTypes.Float32, nil
+	case etensor.FLOAT64:
+		return arrow.PrimitiveTypes.Float64, nil
+	case etensor.STRING:
+		return arrow.BinaryTypes.String, nil
+	}
+	return nil, fmt.Errorf("ArrowType: no Arrow type for etensor type: %v", typ)
+}
+
+// ArrowSchema returns the Arrow schema of given table: a field per column, of the
+// type of its values for scalar columns, and a fixed size list of the values of
+// a cell for tensor columns, whose metadata has the shape of the cell, and the
+// names of its dimensions if any, e.g., shape: 2,8.  The metadata of the
+// table is the metadata of the schema.
+func ArrowSchema(dt *etable.Table) (*arrow.Schema, error) {
+	flds := make([]arrow.Field, len(dt.Cols))
+	for ci, cl := range dt.Cols {
+		typ, err := ArrowType(cl.DataType())
+		if err != nil {
+			return nil, fmt.Errorf("ArrowSchema: column %v: %v", dt.ColNames[ci], err)
+		}
+		fld := arrow.Field{Name: dt.ColNames[ci], Type: typ}
+		if cl.NumDims() > 1 {
+			_, csz := cl.RowCellSize()
+			fld.Type = arrow.FixedSizeListOf(int32(csz), typ)
+			keys := []string{"shape"}
+			vals := []string{joinInts(cl.Shapes()[1:])}
+			if dnm := cl.DimNames()[1:]; len(dnm) > 0 && dnm[0] != "" {
+				keys = append(keys, "dims")
+				vals = append(vals, strings.Join(dnm, ","))
+			}
+			fld.Metadata = arrow.NewMetadata(keys, vals)
+		}
+		flds[ci] = fld
+	}
+	var keys, vals []string
+	for k := range dt.MetaData {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		vals = append(vals, dt.MetaData[k])
+	}
+	md := arrow.NewMetadata(keys, vals)
+	return arrow.NewSchema(flds, &md), nil
+}
+
+// AppendArrowRow appends given row of given table to given record builder, of the
+// ArrowSchema of the table
+func AppendArrowRow(rb *array.RecordBuilder, dt *etable.Table, row int) {
+	for ci, cl := range dt.Cols {
+		fb := rb.Field(ci)
+		_, csz := cl.RowCellSize()
+		if lb, ok := fb.(*array.FixedSizeListBuilder); ok {
+			lb.Append(true)
+			appendArrowVals(lb.ValueBuilder(), cl, row, csz)
+		} else {
+			appendArrowVals(fb, cl, row, 1)
+		}
+	}
+}
+
+// appendArrowVals appends the n values of the cell of given row of given column
+// to given builder
+func appendArrowVals(b array.Builder, cl etensor.Tensor, row, n int) {
+	for i := 0; i < n; i++ {
+		switch vb := b.(type) {
+		case *array.BooleanBuilder:
+			vb.Append(cl.FloatValRowCell(row, i) != 0)
+		case *array.Uint8Builder:
+			vb.Append(uint8(cl.FloatValRowCell(row, i)))
+		case *array.Int8Builder:
+			vb.Append(int8(cl.FloatValRowCell(row, i)))
+		case *array.Uint16Builder:
+			vb.Append(uint16(cl.FloatValRowCell(row, i)))
+		case *array.Int16Builder:
+			vb.Append(int16(cl.FloatValRowCell(row, i)))
+		case *array.Uint32Builder:
+			vb.Append(uint32(cl.FloatValRowCell(row, i)))
+		case *array.Int32Builder:
+			vb.Append(int32(cl.FloatValRowCell(row, i)))
+		case *array.Uint64Builder:
+			vb.Append(uint64(cl.FloatValRowCell(row, i)))
+		case *array.Int64Builder:
+			vb.Append(int64(cl.FloatValRowCell(row, i)))
+		case *array.Float32Builder:
+			vb.Append(float32(cl.FloatValRowCell(row, i)))
+		case *array.Float64Builder:
+			vb.Append(cl.FloatValRowCell(row, i))
+		case *array.StringBuilder:
+			vb.Append(cl.StringValRowCell(row, i))
+		}
+	}
+}
+
+// SaveArrow saves all the rows of given table to an Arrow IPC file of given name,
+// with its ArrowSchema, e.g., to load with pyarrow.feather.read_table
+func SaveArrow(dt *etable.Table, fname string) error {
+	af, err := CreateArrowLogFile(fname, dt, dt.Rows)
+	if err != nil {
+		return err
+	}
+	for row := 0; row < dt.Rows; row++ {
+		af.LogRow(dt, row)
+	}
+	return af.Close()
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////
+// 		ArrowLogFile
+
+// ArrowLogFile is a LogObserver that writes the rows of a log to an Arrow IPC file
+// as they are logged, in record batches of Batch rows, with the ArrowSchema of the
+// log.  It must be closed to write the last batch and the footer of the file.
+type ArrowLogFile struct {
+	Log    *etable.Table        `desc:"the log written to the file"`
+	Name   string               `desc:"name of the file"`
+	Batch  int                  `desc:"number of rows of each record batch"`
+	Schema *arrow.Schema        `desc:"schema of the file, from the columns of the Log"`
+	File   *os.File             `desc:"the file"`
+	Writer *ipc.FileWriter      `desc:"writer of the file"`
+	Bldr   *array.RecordBuilder `desc:"builder of the current record batch"`
+	NRows  int                  `desc:"number of rows in the current record batch"`
+}
+
+// CreateArrowLogFile creates the Arrow IPC file of given name for given log, with
+// record batches of given number of rows, ArrowBatch if <= 0
+func CreateArrowLogFile(fname string, dt *etable.Table, batch int) (*ArrowLogFile, error) {
+	sc, err := ArrowSchema(dt)
+	if err != nil {
+		return nil, err
+	}
+	if batch <= 0 {
+		batch = ArrowBatch
+	}
+	f, err := os.Create(fname)
+	if err != nil {
+		return nil, err
+	}
+	w, err := ipc.NewFileWriter(f, ipc.WithSchema(sc), ipc.WithAllocator(memory.DefaultAllocator))
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	af := &ArrowLogFile{Log: dt, Name: fname, Batch: batch, Schema: sc, File: f, Writer: w}
+	af.Bldr = array.NewRecordBuilder(memory.DefaultAllocator, sc)
+	return af, nil
+}
+
+// LogRow appends given row of the log to the current record batch, and writes it
+// when it has Batch rows
+func (af *ArrowLogFile) LogRow(dt *etable.Table, row int) {
+	if dt != af.Log || row < 0 || af.Writer == nil {
+		return
+	}
+	if len(dt.Cols) != len(af.Schema.Fields()) {
+		log.Printf("ArrowLogFile: %v: the columns of the log changed, its rows are no longer written\n", af.Name)
+		af.Close()
+		return
+	}
+	AppendArrowRow(af.Bldr, dt, row)
+	af.NRows++
+	if af.NRows >= af.Batch {
+		if err := af.Flush(); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// Flush writes the rows of the current record batch, if any
+func (af *ArrowLogFile) Flush() error {
+	if af.NRows == 0 || af.Writer == nil {
+		return nil
+	}
+	rec := af.Bldr.NewRecord()
+	defer rec.Release()
+	af.NRows = 0
+	return af.Writer.Write(rec)
+}
+
+// Close writes the current record batch and the footer of the file, and closes it
+func (af *ArrowLogFile) Close() error {
+	if af.Writer == nil {
+		return nil
+	}
+	err := af.Flush()
+	if cerr := af.Writer.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := af.File.Close(); err == nil {
+		err = cerr
+	}
+	af.Bldr.Release()
+	af.Writer = nil
+	af.File = nil
+	return err
+}
+
+// OpenArrowLogFile creates the Arrow IPC file of given name to write the rows of
+// given log to as they are logged, and subscribes it to the log.  If the Sim was
+// Resumed from a checkpoint, the rows of any existing file of that name that were
+// logged before the checkpoint are kept, see ResumeArrowLogFile.  The file is
+// recorded in the LogFiles of the Manifest.
+func (ss *Sim) OpenArrowLogFile(fname string, dt *etable.Table) (*ArrowLogFile, error) {
+	var af *ArrowLogFile
+	var err error
+	if ss.Resumed {
+		af, err = ss.ResumeArrowLogFile(fname, dt)
+	} else {
+		af, err = CreateArrowLogFile(fname, dt, 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ss.LogFiles = append(ss.LogFiles, fname)
+	ss.Observe(af, dt)
+	return af, nil
+}
+
+// ResumeArrowLogFile creates the Arrow IPC file of given name for given log of a
+// Sim restored from a checkpoint, keeping the rows of any existing file of that
+// name that were logged before the checkpoint: those of the runs before it, and
+// those of the epochs before it in its run, if the log has an Epoch column.  If
+// there is no such file, or it cannot be read, as it is only complete once closed,
+// the rows of the log restored from the checkpoint are written instead, as by
+// ResumeLogFile.
+func (ss *Sim) ResumeArrowLogFile(fname string, dt *etable.Table) (*ArrowLogFile, error) {
+	b, rerr := ioutil.ReadFile(fname)
+	var rd *ipc.FileReader
+	if rerr == nil {
+		var err error
+		rd, err = ipc.NewFileReader(bytes.NewReader(b), ipc.WithAllocator(memory.DefaultAllocator))
+		if err != nil { // e.g., not closed as the run was killed
+			log.Printf("ResumeArrowLogFile: %v: cannot read its rows, only those restored from the checkpoint are kept: %v\n", fname, err)
+			rd = nil
+		} else {
+			defer rd.Close()
+		}
+	}
+	af, err := CreateArrowLogFile(fname, dt, 0)
+	if err != nil {
+		return nil, err
+	}
+	if rd == nil {
+		for row := 0; row < dt.Rows; row++ {
+			af.LogRow(dt, row)
+		}
+		return af, nil
+	}
+	if !rd.Schema().Equal(af.Schema) {
+		af.Close()
+		return nil, fmt.Errorf("ResumeArrowLogFile: %v: the columns of the file differ from those of the log", fname)
+	}
+	trn := ss.TrainEnv.Ctrs()
+	run, epc := trn.Run.Cur, trn.Epoch.Cur
+	for i := 0; i < rd.NumRecords(); i++ {
+		rec, err := rd.Record(i)
+		if err != nil {
+			af.Close()
+			return nil, err
+		}
+		n := arrowRowsBefore(rec, run, epc)
+		if n > 0 {
+			sl := rec.NewSlice(0, int64(n))
+			err = af.Writer.Write(sl)
+			sl.Release()
+		}
+		if err != nil {
+			af.Close()
+			return nil, err
+		}
+		if n < int(rec.NumRows()) {
+			break
+		}
+	}
+	return af, nil
+}
+
+// arrowRowsBefore returns the number of leading rows of given record that were
+// logged before given run and epoch, by its Run and Epoch columns
+func arrowRowsBefore(rec array.Record, run, epc int) int {
+	var runs, epcs array.Interface
+	for ci, fld := range rec.Schema().Fields() {
+		switch fld.Name {
+		case "Run":
+			runs = rec.Column(ci)
+		case "Epoch":
+			epcs = rec.Column(ci)
+		}
+	}
+	if runs == nil {
+		return 0
+	}
+	n := int(rec.NumRows())
+	for row := 0; row < n; row++ {
+		r := int(arrowFloat(runs, row))
+		if r < run {
+			continue
+		}
+		if r == run && epcs != nil && int(arrowFloat(epcs, row)) < epc {
+			continue
+		}
+		return row
+	}
+	return n
+}
+
+// arrowFloat returns the value of given row of given numeric array, as a float64
+func arrowFloat(arr array.Interface, row int) float64 {
+	switch a := arr.(type) {
+	case *array.Int64:
+		return float64(a.Value(row))
+	case *array.Int32:
+		return float64(a.Value(row))
+	case *array.Float64:
+		return a.Value(row)
+	case *array.Float32:
+		return float64(a.Value(row))
+	}
+	return 0
+}
+
+// joinInts returns the given ints separated by commas
+func joinInts(vals []int) string {
+	strs := make([]string, len(vals))
+	for i, v := range vals {
+		strs[i] = strconv.Itoa(v)
+	}
+	return strings.Join(strs, ",")
+}
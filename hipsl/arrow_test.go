@@ -0,0 +1,159 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// newArrowTable returns a log of 5 rows of runs 0 and 1, with a scalar column of
+// each kind and a tensor column
+func newArrowTable() *etable.Table {
+	dt := &etable.Table{}
+	dt.SetMetaData("name", "TestLog")
+	dt.SetMetaData("desc", "test log")
+	dt.SetFromSchema(etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"Name", etensor.STRING, nil, nil},
+		{"Mem", etensor.FLOAT64, nil, nil},
+		{"Act", etensor.FLOAT32, []int{2, 3}, []string{"Y", "X"}},
+	}, 5)
+	for row := 0; row < dt.Rows; row++ {
+		dt.SetCellFloat("Run", row, float64(row/3))
+		dt.SetCellFloat("Epoch", row, float64(row%3))
+		dt.SetCellString("Name", row, string(rune('A'+row)))
+		dt.SetCellFloat("Mem", row, float64(row)/4)
+		act := dt.CellTensor("Act", row)
+		for i := 0; i < act.Len(); i++ {
+			act.SetFloat1D(i, float64(row*10+i))
+		}
+	}
+	return dt
+}
+
+func TestArrowSchema(t *testing.T) {
+	dt := newArrowTable()
+	sc, err := ArrowSchema(dt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []arrow.DataType{arrow.PrimitiveTypes.Int64, arrow.PrimitiveTypes.Int64, arrow.BinaryTypes.String, arrow.PrimitiveTypes.Float64, arrow.FixedSizeListOf(6, arrow.PrimitiveTypes.Float32)}
+	if len(sc.Fields()) != len(want) {
+		t.Fatalf("%d fields, want %d", len(sc.Fields()), len(want))
+	}
+	for i, fld := range sc.Fields() {
+		if fld.Name != dt.ColNames[i] || !arrow.TypeEqual(fld.Type, want[i]) {
+			t.Errorf("field %d: %s %v, want %s %v", i, fld.Name, fld.Type, dt.ColNames[i], want[i])
+		}
+	}
+	md := sc.Field(4).Metadata
+	if md.Len() != 2 || md.Values()[md.FindKey("shape")] != "2,3" || md.Values()[md.FindKey("dims")] != "Y,X" {
+		t.Errorf("Act metadata: %v", md)
+	}
+	if sc.Field(0).HasMetadata() {
+		t.Errorf("Run metadata: %v", sc.Field(0).Metadata)
+	}
+	smd := sc.Metadata()
+	if smd.Len() != 2 || smd.Keys()[0] != "desc" || smd.Values()[smd.FindKey("name")] != "TestLog" {
+		t.Errorf("schema metadata: %v", smd)
+	}
+}
+
+// readArrow returns a reader of given Arrow IPC file
+func readArrow(t *testing.T, fname string) *ipc.FileReader {
+	t.Helper()
+	b, err := ioutil.ReadFile(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rd, err := ipc.NewFileReader(bytes.NewReader(b), ipc.WithAllocator(memory.DefaultAllocator))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rd
+}
+
+func TestArrowRoundTrip(t *testing.T) {
+	dt := newArrowTable()
+	fname := filepath.Join(t.TempDir(), "test.arrow")
+	af, err := CreateArrowLogFile(fname, dt, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for row := 0; row < dt.Rows; row++ {
+		af.LogRow(dt, row)
+	}
+	af.LogRow(dt, -1)             // whole log updated: nothing to write
+	af.LogRow(newArrowTable(), 0) // not its log
+	if err := af.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rd := readArrow(t, fname)
+	defer rd.Close()
+	if !rd.Schema().Equal(af.Schema) {
+		t.Errorf("schema of the file: %v", rd.Schema())
+	}
+	if rd.NumRecords() != 3 {
+		t.Errorf("%d record batches, want 3", rd.NumRecords())
+	}
+	row := 0
+	for i := 0; i < rd.NumRecords(); i++ {
+		rec, err := rd.Record(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		run := rec.Column(0).(*array.Int64)
+		nms := rec.Column(2).(*array.String)
+		mem := rec.Column(3).(*array.Float64)
+		act := rec.Column(4).(*array.FixedSizeList)
+		vals := act.ListValues().(*array.Float32)
+		for r := 0; r < int(rec.NumRows()); r, row = r+1, row+1 {
+			if run.Value(r) != int64(dt.CellFloat("Run", row)) || nms.Value(r) != dt.CellString("Name", row) || mem.Value(r) != dt.CellFloat("Mem", row) {
+				t.Errorf("row %d: %d %s %g", row, run.Value(r), nms.Value(r), mem.Value(r))
+			}
+			cell := dt.CellTensor("Act", row)
+			off := int(act.Offset()) + r // fixed size lists of 6
+			for c := 0; c < 6; c++ {
+				if v := vals.Value(off*6 + c); float64(v) != cell.FloatVal1D(c) {
+					t.Errorf("row %d Act %d: %g, want %g", row, c, v, cell.FloatVal1D(c))
+				}
+			}
+		}
+	}
+	if row != dt.Rows {
+		t.Errorf("%d rows read, want %d", row, dt.Rows)
+	}
+
+	sfn := filepath.Join(t.TempDir(), "save.arrow")
+	if err := SaveArrow(dt, sfn); err != nil {
+		t.Fatal(err)
+	}
+	srd := readArrow(t, sfn)
+	defer srd.Close()
+	rec, err := srd.Record(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if srd.NumRecords() != 1 || rec.NumRows() != int64(dt.Rows) {
+		t.Errorf("SaveArrow: %d records of %d rows", srd.NumRecords(), rec.NumRows())
+	}
+	for _, re := range []struct{ run, epc, n int }{{0, 0, 0}, {0, 2, 2}, {1, 0, 3}, {1, 1, 4}, {2, 0, 5}} {
+		if n := arrowRowsBefore(rec, re.run, re.epc); n != re.n {
+			t.Errorf("rows before run %d epoch %d: %d, want %d", re.run, re.epc, n, re.n)
+		}
+	}
+}
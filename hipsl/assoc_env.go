@@ -0,0 +1,183 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"fmt"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// AssocProbes are the triad positions (0 = A, 1 = B, 2 = C) of the cue and
+// target items of each associative inference test, by test name
+var AssocProbes = map[string][2]int{"AB": {0, 1}, "BC": {1, 2}, "AC": {0, 2}}
+
+// AssocEnv generates the training stream for the associative inference paradigm
+// simulated in Schapiro et al. (2017).  Items are organized in triads ABC, and
+// each trial presents one of the two overlapping pairs of a random triad, AB or BC,
+// with both items active at ItemAct on Input and ECout.  The A and C items of a
+// triad are never presented together, so that A -> C completion at test
+// requires inference across the shared B item.
+type AssocEnv struct {
+	SeqCtrs
+	Items   []string        `desc:"names of the items -- item i is represented by unit i in the patterns"`
+	Triads  [][3]int        `desc:"triad assignment: indexes into Items of the A, B, and C item of each triad"`
+	ItemAct float32         `desc:"activity of each item in the patterns"`
+	NUnits  int             `desc:"number of units in the patterns -- if 0, uses number of Items"`
+	Triad   int             `inactive:"+" desc:"index of the current triad"`
+	BC      bool            `inactive:"+" desc:"true if the current pair is BC, otherwise AB"`
+	Pattern etensor.Float32 `desc:"current pattern, applied to Input and ECout -- TrialName is the pair of item names (e.g., AB)"`
+}
+
+// Defaults sets the 12 items A-L in triads ABC, DEF, GHI, JKL
+func (ev *AssocEnv) Defaults() {
+	ev.Items = []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "L"}
+	ev.Triads = [][3]int{{0, 1, 2}, {3, 4, 5}, {6, 7, 8}, {9, 10, 11}}
+	ev.ItemAct = 1
+}
+
+func (ev *AssocEnv) Validate() error {
+	ni := len(ev.Items)
+	if ni == 0 {
+		return fmt.Errorf("AssocEnv: %v has no Items", ev.Nm)
+	}
+	if len(ev.Triads) == 0 {
+		return fmt.Errorf("AssocEnv: %v has no Triads", ev.Nm)
+	}
+	if ev.NUnits > 0 && ev.NUnits < ni {
+		return fmt.Errorf("AssocEnv: %v NUnits %d < number of Items %d", ev.Nm, ev.NUnits, ni)
+	}
+	triadOf := make([]int, ni)
+	for i := range triadOf {
+		triadOf[i] = -1
+	}
+	for ti, tr := range ev.Triads {
+		for _, it := range tr {
+			if it < 0 || it >= ni {
+				return fmt.Errorf("AssocEnv: %v triad %d item index %d out of range", ev.Nm, ti, it)
+			}
+			if triadOf[it] >= 0 {
+				return fmt.Errorf("AssocEnv: %v item %s is in more than one triad position", ev.Nm, ev.Items[it])
+			}
+			triadOf[it] = ti
+		}
+	}
+	return nil
+}
+
+// PatSize returns the number of units in the patterns
+func (ev *AssocEnv) PatSize() int {
+	if ev.NUnits > 0 {
+		return ev.NUnits
+	}
+	return len(ev.Items)
+}
+
+func (ev *AssocEnv) Init(run int) {
+	ev.Pattern.SetShape([]int{1, 1, ev.PatSize(), 1}, nil, nil)
+	ev.InitCtrs(run)
+}
+
+// ItemNames returns the names of the items
+func (ev *AssocEnv) ItemNames() []string {
+	return ev.Items
+}
+
+// TriadName returns the name of given triad, e.g., ABC
+func (ev *AssocEnv) TriadName(ti int) string {
+	tr := ev.Triads[ti]
+	return ev.Items[tr[0]] + ev.Items[tr[1]] + ev.Items[tr[2]]
+}
+
+// ProbeItems returns the indexes of the cue and target items of given
+// AssocProbes test for the triad of given name -- -1 if not found
+func (ev *AssocEnv) ProbeItems(triad, probe string) (cue, trg int) {
+	pr, ok := AssocProbes[probe]
+	if !ok {
+		return -1, -1
+	}
+	for ti, tr := range ev.Triads {
+		if ev.TriadName(ti) == triad {
+			return tr[pr[0]], tr[pr[1]]
+		}
+	}
+	return -1, -1
+}
+
+// SetPattern sets the Pattern and TrialName from the current Triad and pair
+func (ev *AssocEnv) SetPattern() {
+	tr := ev.Triads[ev.Triad]
+	a, b := tr[0], tr[1]
+	if ev.BC {
+		a, b = tr[1], tr[2]
+	}
+	SetOverlapPattern(&ev.Pattern, a, b, ev.ItemAct, ev.ItemAct)
+	ev.TrialName.Set(ev.Items[a] + ev.Items[b])
+}
+
+func (ev *AssocEnv) Step() bool {
+	ev.StepCtrs()
+	ev.Triad = ev.Rand.Intn(len(ev.Triads))
+	ev.BC = ev.Rand.Intn(2) == 1
+	ev.SetPattern()
+	return true
+}
+
+func (ev *AssocEnv) State(element string) etensor.Tensor {
+	switch element {
+	case "Input", "ECout":
+		return &ev.Pattern
+	}
+	return nil
+}
+
+// ConfigTestTable configures given table with one test pattern per triad,
+// presenting the item at position cue of the triad (0 = A, 1 = B, 2 = C) on Input,
+// with the cue and the item at position trg as the ECout target.
+// The Group column is the name of the triad.
+func (ev *AssocEnv) ConfigTestTable(dt *etable.Table, name string, cue, trg int) {
+	nt := len(ev.Triads)
+	shp := []int{1, 1, ev.PatSize(), 1}
+	sch := etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Group", etensor.STRING, nil, nil},
+		{"Input", etensor.FLOAT32, shp, nil},
+		{"ECout", etensor.FLOAT32, shp, nil},
+	}
+	dt.SetFromSchema(sch, nt)
+	dt.SetMetaData("name", name+" Testing Patterns")
+	dt.SetMetaData("desc", name+" Testing Patterns")
+	in := dt.ColByName("Input").(*etensor.Float32)
+	out := dt.ColByName("ECout").(*etensor.Float32)
+	sz := ev.PatSize()
+	for ti, tr := range ev.Triads {
+		dt.SetCellString("Name", ti, ev.Items[tr[cue]]+ev.Items[tr[trg]])
+		dt.SetCellString("Group", ti, ev.TriadName(ti))
+		in.Values[ti*sz+tr[cue]] = ev.ItemAct
+		out.Values[ti*sz+tr[cue]] = ev.ItemAct
+		out.Values[ti*sz+tr[trg]] = ev.ItemAct
+	}
+}
+
+// ConfigRecogTable configures given table with the items of the recognition test:
+// the AB and BC pairs of each triad as targets, and as lures, the across-pair
+// pairings of the A and B items of each triad with the B and C items of the next
+// triad (e.g., AE, BF), and the novel combinations of the A item of each triad
+// with the C item of the next (e.g., AF), none of which are ever presented together
+func (ev *AssocEnv) ConfigRecogTable(dt *etable.Table) {
+	nt := len(ev.Triads)
+	rp := &RecogPairs{}
+	for ti, tr := range ev.Triads {
+		nx := ev.Triads[(ti+1)%nt]
+		rp.Target = append(rp.Target, [2]int{tr[0], tr[1]}, [2]int{tr[1], tr[2]})
+		rp.Across = append(rp.Across, [2]int{tr[0], nx[1]}, [2]int{tr[1], nx[2]})
+		rp.Novel = append(rp.Novel, [2]int{tr[0], nx[2]})
+	}
+	ConfigRecogTable(dt, ev.Items, ev.PatSize(), rp, ev.ItemAct, ev.ItemAct)
+}
+
+// Compile-time check that implements SeqEnv interface
+var _ SeqEnv = (*AssocEnv)(nil)
@@ -0,0 +1,143 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"math"
+	"testing"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+func TestAssocEnvStream(t *testing.T) {
+	ev := &AssocEnv{}
+	ev.Defaults()
+	ev.Trial.Max = 100
+	if err := ev.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	ev.SetSeed(1)
+	ev.Init(0)
+	n := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		ev.Step()
+		tr := ev.Triads[ev.Triad]
+		a, b := tr[0], tr[1]
+		if ev.BC {
+			a, b = tr[1], tr[2]
+		}
+		if nm := ev.Items[a] + ev.Items[b]; ev.TrialName.Cur != nm {
+			t.Fatalf("TrialName %s, want %s", ev.TrialName.Cur, nm)
+		}
+		for ui, v := range ev.Pattern.Values {
+			want := float32(0)
+			if ui == a || ui == b {
+				want = ev.ItemAct
+			}
+			if v != want {
+				t.Fatalf("%s: unit %d is %g, want %g", ev.TrialName.Cur, ui, v, want)
+			}
+		}
+		if ev.Pattern.Values[tr[0]] != 0 && ev.Pattern.Values[tr[2]] != 0 {
+			t.Fatalf("A and C items of triad %s presented together", ev.TriadName(ev.Triad))
+		}
+		n[ev.TrialName.Cur]++
+	}
+	if len(n) != 8 {
+		t.Errorf("presented %d different pairs, want the 8 AB and BC pairs: %v", len(n), n)
+	}
+}
+
+func TestAssocEnvTestTable(t *testing.T) {
+	ev := &AssocEnv{}
+	ev.Defaults()
+	dt := &etable.Table{}
+	ev.ConfigTestTable(dt, "AC", 0, 2)
+	if dt.Rows != 4 {
+		t.Fatalf("%d rows, want 4", dt.Rows)
+	}
+	in := dt.ColByName("Input").(*etensor.Float32)
+	out := dt.ColByName("ECout").(*etensor.Float32)
+	sz := ev.PatSize()
+	for ti, tr := range ev.Triads {
+		if nm := dt.CellString("Name", ti); nm != ev.Items[tr[0]]+ev.Items[tr[2]] {
+			t.Errorf("row %d: Name %s", ti, nm)
+		}
+		if grp := dt.CellString("Group", ti); grp != ev.TriadName(ti) {
+			t.Errorf("row %d: Group %s", ti, grp)
+		}
+		for ui := 0; ui < sz; ui++ {
+			win, wout := float32(0), float32(0)
+			if ui == tr[0] {
+				win, wout = 1, 1
+			} else if ui == tr[2] {
+				wout = 1
+			}
+			if in.Values[ti*sz+ui] != win || out.Values[ti*sz+ui] != wout {
+				t.Errorf("row %d unit %d: Input %g ECout %g, want %g %g", ti, ui, in.Values[ti*sz+ui], out.Values[ti*sz+ui], win, wout)
+			}
+		}
+	}
+	if cue, trg := ev.ProbeItems("DEF", "AC"); cue != 3 || trg != 5 {
+		t.Errorf("ProbeItems(DEF, AC) = %d, %d, want 3, 5", cue, trg)
+	}
+	if cue, trg := ev.ProbeItems("XYZ", "AC"); cue != -1 || trg != -1 {
+		t.Errorf("ProbeItems(XYZ, AC) = %d, %d, want -1, -1", cue, trg)
+	}
+}
+
+func TestAssocEnvValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		set  func(ev *AssocEnv)
+	}{
+		{"no items", func(ev *AssocEnv) { ev.Items = nil }},
+		{"no triads", func(ev *AssocEnv) { ev.Triads = nil }},
+		{"item out of range", func(ev *AssocEnv) { ev.Triads[0][2] = 12 }},
+		{"item in two triads", func(ev *AssocEnv) { ev.Triads[1][1] = 1 }},
+		{"item twice in a triad", func(ev *AssocEnv) { ev.Triads[0][2] = 0 }},
+		{"too few units", func(ev *AssocEnv) { ev.NUnits = 6 }},
+	} {
+		ev := &AssocEnv{}
+		ev.Defaults()
+		tc.set(ev)
+		if err := ev.Validate(); err == nil {
+			t.Errorf("%s: Validate did not fail", tc.name)
+		}
+	}
+}
+
+func TestInferStat(t *testing.T) {
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"TestNm", etensor.STRING, nil, nil},
+		{"Group", etensor.STRING, nil, nil},
+		{"Mem", etensor.FLOAT64, nil, nil},
+	}, 0)
+	add := func(tst, grp string, mem float64) {
+		row := dt.Rows
+		dt.SetNumRows(row + 1)
+		dt.SetCellString("TestNm", row, tst)
+		dt.SetCellString("Group", row, grp)
+		dt.SetCellFloat("Mem", row, mem)
+	}
+	ss := &Sim{}
+	add("AB", "ABC", 1)
+	add("AB", "DEF", 1)
+	add("BC", "DEF", 0)
+	if inf := ss.InferStat(dt); !math.IsNaN(inf) {
+		t.Errorf("InferStat with no triad with both pairs = %g, want NaN", inf)
+	}
+	add("BC", "ABC", 1)
+	add("AB", "GHI", 1)
+	add("BC", "GHI", 1)
+	add("AC", "ABC", 1)
+	add("AC", "DEF", 1)
+	add("AC", "GHI", 0)
+	if inf := ss.InferStat(dt); inf != 0.5 {
+		t.Errorf("InferStat = %g, want 0.5", inf)
+	}
+}
@@ -0,0 +1,400 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/emer/emergent/params"
+	"github.com/emer/etable/etable"
+	"github.com/emer/leabra/leabra"
+	"github.com/goki/gi/gi"
+)
+
+// Checkpoint is the state of the Sim saved in the ckpt.json file of a checkpoint
+// directory, along with the network weights (net.wts.gz), the full network state
+// needed to continue learning exactly (net.state.gz), and the logs accumulated so
+// far (one .tsv file per log).  Checkpoints are taken at the start of an epoch,
+// after the TrainEnv has been stepped into its first trial.
+type Checkpoint struct {
+	Paradigm     string          `desc:"name of the Paradigm"`
+	Topology     Topology        `desc:"topology of the network"`
+	Lesions      string          `desc:"lesions of the network, as parsed by ParseLesions"`
+	ParamSet     string          `desc:"ParamSet in use"`
+	Params       params.Sets     `desc:"param sets applied: Base and the ParamSet, as merged with any params files -- replace those of the Sim on resume"`
+	Tag          string          `desc:"Tag of the run"`
+	RndSeed      int64           `desc:"master random seed"`
+	Seeds        RunSeeds        `desc:"seeds of the current run"`
+	StartRun     int             `desc:"first run to do"`
+	MaxRuns      int             `desc:"maximum number of runs"`
+	MaxEpcs      int             `desc:"maximum number of epochs per run"`
+	TrialperEpc  int             `desc:"number of trials per epoch"`
+	NZeroStop    int             `desc:"number of epochs with zero Mem err after which training stops"`
+	TestInterval int             `desc:"how often to test, in epochs"`
+	MemThr       float64         `desc:"threshold of the memory criterion"`
+	RecogOn      bool            `desc:"whether the recognition test is run"`
+	RecogThr     float64         `desc:"threshold of the recognition test"`
+	Replay       Replay          `desc:"replay phase settings"`
+	WtSnap       WtSnap          `desc:"weight snapshot settings"`
+	Run          int             `desc:"current run"`
+	Epoch        int             `desc:"current epoch"`
+	FirstZero    int             `desc:"epoch at which Mem err first went to zero"`
+	NZero        int             `desc:"number of epochs in a row with zero Mem err"`
+	Time         leabra.Time     `desc:"leabra timing state"`
+	EnvRand      RandState       `desc:"state of the random number generator of the TrainEnv"`
+	ReplayRand   RandState       `desc:"state of the random number generator of the Replay"`
+	Env          json.RawMessage `desc:"full state of the TrainEnv, including its counters"`
+}
+
+// CkptLogs returns the logs saved in checkpoints, by file name, along with the
+// RSA patterns of the last test, to which the reactivations of Replay are matched
+func (ss *Sim) CkptLogs() map[string]*etable.Table {
+	return map[string]*etable.Table{
+		"trnepc.tsv":  ss.TrnEpcLog,
+		"tstepc.tsv":  ss.TstEpcLog,
+		"rsa.tsv":     ss.RSALog,
+		"rsapats.tsv": ss.RSA.Pats,
+		"replay.tsv":  ss.ReplayLog,
+		"wtepc.tsv":   ss.WtEpcLog,
+		"run.tsv":     ss.RunLog,
+	}
+}
+
+// CkptDirName returns the checkpoint directory: CkptDir if set, otherwise
+// named after the network and the RunName
+func (ss *Sim) CkptDirName() string {
+	if ss.CkptDir != "" {
+		return ss.CkptDir
+	}
+	return ss.Net.Nm + "_" + ss.RunName() + "_ckpt"
+}
+
+// SaveCkpt saves a checkpoint into CkptDirName, replacing any previous one --
+// it is first written to a temporary directory, so that an interruption while
+// saving leaves the previous checkpoint intact
+func (ss *Sim) SaveCkpt() error {
+	dir := ss.CkptDirName()
+	tmp := dir + ".tmp"
+	os.RemoveAll(tmp)
+	if err := os.MkdirAll(tmp, os.ModePerm); err != nil {
+		return err
+	}
+	trn := ss.TrainEnv.Ctrs()
+	ck := &Checkpoint{Paradigm: ss.Paradigm.String(), Topology: ss.Topology, Lesions: ss.Lesions.String(), ParamSet: ss.ParamSet, Params: ss.AppliedSets(), Tag: ss.Tag, RndSeed: ss.RndSeed, Seeds: ss.Seeds, StartRun: ss.StartRun, MaxRuns: ss.MaxRuns, MaxEpcs: ss.MaxEpcs, TrialperEpc: ss.TrialperEpc, NZeroStop: ss.NZeroStop, TestInterval: ss.TestInterval, MemThr: ss.MemThr, RecogOn: ss.RecogOn, RecogThr: ss.RecogThr, Replay: ss.Replay, WtSnap: ss.WtSnap, Run: trn.Run.Cur, Epoch: trn.Epoch.Cur, FirstZero: ss.FirstZero, NZero: ss.NZero, Time: ss.Time, EnvRand: trn.Src.State, ReplayRand: ss.Replay.Src.State}
+	var err error
+	ck.Env, err = MarshalSeqEnv(ss.TrainEnv)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(ck, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(tmp, "ckpt.json"), b, 0644); err != nil {
+		return err
+	}
+	if err := ss.Net.SaveWtsJSON(gi.FileName(filepath.Join(tmp, "net.wts.gz"))); err != nil {
+		return err
+	}
+	if err := SaveNetState(ss.Net, filepath.Join(tmp, "net.state.gz")); err != nil {
+		return err
+	}
+	for fnm, dt := range ss.CkptLogs() {
+		if err := SaveLogExact(dt, filepath.Join(tmp, fnm)); err != nil {
+			return err
+		}
+	}
+	os.RemoveAll(dir)
+	return os.Rename(tmp, dir)
+}
+
+// ResumeCkpt restores the checkpoint in given directory, including the Paradigm,
+// Topology, Lesions, params, seeds, and testing, stopping, Replay and WtSnap
+// settings it was run with, so that training continues from it exactly as
+// it would have without interruption, whatever params files and settings the Sim
+// has.  Activity recording by ActRec restarts a new file for the run.
+func (ss *Sim) ResumeCkpt(dir string) error {
+	b, err := ioutil.ReadFile(filepath.Join(dir, "ckpt.json"))
+	if err != nil {
+		return err
+	}
+	ck := &Checkpoint{}
+	if err := json.Unmarshal(b, ck); err != nil {
+		return err
+	}
+	var pd Paradigms
+	if err := pd.FromString(ck.Paradigm); err != nil {
+		return err
+	}
+	ss.ParamSet = ck.ParamSet
+	ss.Tag = ck.Tag
+	ss.RndSeed = ck.RndSeed
+	ss.StartRun = ck.StartRun
+	ss.MaxRuns = ck.MaxRuns
+	ss.MaxEpcs = ck.MaxEpcs
+	ss.TrialperEpc = ck.TrialperEpc
+	for _, ps := range ck.Params {
+		ss.Params = ReplaceParamsSet(ss.Params, ps)
+	}
+	ss.NZeroStop = ck.NZeroStop
+	ss.TestInterval = ck.TestInterval
+	ss.MemThr = ck.MemThr
+	ss.RecogOn = ck.RecogOn // logs configured by SetParadigm
+	ss.RecogThr = ck.RecogThr
+	ss.Replay = ck.Replay // seeded by NewRun
+	ss.WtSnap = ck.WtSnap
+	if err := ck.Topology.Validate(); err != nil {
+		return err
+	}
+	ss.Topology = ck.Topology
+	lss, err := ParseLesions(ck.Lesions)
+	if err != nil {
+		return err
+	}
+	ss.Lesions = lss // validated for the network by SetParadigm
+
+	// the env config determines the size of the network, so set it before SetParadigm
+	ss.Paradigm = pd
+	ss.ConfigEnv()
+	if err := json.Unmarshal(ck.Env, ss.TrainEnv); err != nil {
+		return err
+	}
+	ss.SetParadigm(pd)
+	ss.StopNow = false
+	ss.SetParams("", ss.LogSetParams)
+	ss.Manifest.RecordParams(ss.Net, ss.AppliedSets())
+	ss.TrainEnv.Ctrs().Run.Cur = ck.Run
+	ss.NewRun() // connectivity of the run, from its seeds
+	ss.Manifest.Resumed = dir
+	if ss.Seeds != ck.Seeds {
+		return fmt.Errorf("ResumeCkpt: seeds of run %d: %v do not match the checkpoint: %v", ck.Run, ss.Seeds, ck.Seeds)
+	}
+
+	if err := json.Unmarshal(ck.Env, ss.TrainEnv); err != nil {
+		return err
+	}
+	ss.TrainEnv.Ctrs().Src.SetState(ck.EnvRand)
+	ss.Replay.Src.SetState(ck.ReplayRand)
+	if err := OpenNetState(ss.Net, filepath.Join(dir, "net.state.gz")); err != nil {
+		return err
+	}
+	ss.WtSnap.Snap(ss.WtSnapPrjns()) // the weights at the end of the checkpoint epoch
+	for fnm, dt := range ss.CkptLogs() {
+		if err := dt.OpenCSV(gi.FileName(filepath.Join(dir, fnm)), etable.Tab); err != nil {
+			return err
+		}
+	}
+	ss.FirstZero = ck.FirstZero
+	ss.NZero = ck.NZero
+	ss.Time = ck.Time
+	ss.Resumed = true
+	ss.LogUpdated(ss.TrnEpcLog, -1)
+	ss.LogUpdated(ss.TstEpcLog, -1)
+	ss.LogUpdated(ss.RSALog, -1)
+	ss.LogUpdated(ss.ReplayLog, -1)
+	ss.LogUpdated(ss.WtEpcLog, -1)
+	ss.LogUpdated(ss.RunLog, -1)
+	ss.UpdateView(true)
+	return nil
+}
+
+// Resume restores the checkpoint in given directory -- see ResumeCkpt
+func (ss *Sim) Resume(dir gi.FileName) {
+	if err := ss.ResumeCkpt(string(dir)); err != nil {
+		log.Println(err)
+	}
+}
+
+// ResumeLogFile creates the log file of given name for given log restored from a
+// checkpoint, keeping the rows of the runs before those in the log from any
+// existing file of that name, followed by the rows of the log, so that the file
+// continues from the checkpoint.  Returns true if headers were written.
+// All logs start with the Run column.
+func (ss *Sim) ResumeLogFile(fnm string, dt *etable.Table) (*os.File, bool, error) {
+	run := ss.TrainEnv.Ctrs().Run.Cur
+	if dt.Rows > 0 {
+		run = int(dt.CellFloat("Run", 0))
+	}
+	var keep []string
+	if b, err := ioutil.ReadFile(fnm); err == nil {
+		for li, ln := range strings.Split(strings.TrimSuffix(string(b), "\n"), "\n") {
+			if li == 0 {
+				keep = append(keep, ln)
+				continue
+			}
+			r, err := strconv.Atoi(strings.SplitN(ln, "\t", 2)[0])
+			if err == nil && r < run {
+				keep = append(keep, ln)
+			}
+		}
+	}
+	if len(keep) == 1 { // only headers
+		keep = nil
+	}
+	f, err := os.Create(fnm)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, ln := range keep {
+		fmt.Fprintln(f, ln)
+	}
+	if dt.Rows == 0 {
+		return f, len(keep) > 0, nil
+	}
+	if len(keep) == 0 {
+		dt.WriteCSVHeaders(f, etable.Tab)
+	}
+	for row := 0; row < dt.Rows; row++ {
+		dt.WriteCSVRow(f, row, etable.Tab)
+	}
+	return f, true, nil
+}
+
+// MarshalSeqEnv returns the JSON encoding of given env, without the Scale of its
+// counters, which is set by Init and cannot be decoded by the env package
+func MarshalSeqEnv(ev SeqEnv) ([]byte, error) {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return nil, err
+	}
+	var flds map[string]json.RawMessage
+	if err := json.Unmarshal(b, &flds); err != nil {
+		return nil, err
+	}
+	for _, cnm := range []string{"Run", "Epoch", "Trial"} {
+		var ctr map[string]json.RawMessage
+		if err := json.Unmarshal(flds[cnm], &ctr); err != nil {
+			return nil, err
+		}
+		delete(ctr, "Scale")
+		if flds[cnm], err = json.Marshal(ctr); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(flds)
+}
+
+// SaveLogExact saves given log to a tab-separated file, at full precision
+// regardless of its precision meta data, so that it is restored exactly
+func SaveLogExact(dt *etable.Table, fname string) error {
+	prec, has := dt.MetaData["precision"]
+	delete(dt.MetaData, "precision")
+	err := dt.SaveCSV(gi.FileName(fname), etable.Tab, etable.Headers)
+	if has {
+		dt.SetMetaData("precision", prec)
+	}
+	return err
+}
+
+// layerState is the state of a layer and its receiving projections, which
+// goes beyond the weights saved by SaveWtsJSON: running averages, learning
+// state of the synapses, and pending weight changes
+type layerState struct {
+	Name    string
+	Neurons []leabra.Neuron
+	Pools   []leabra.Pool
+	CosDiff leabra.CosDiffStats
+	Prjns   []prjnState
+}
+
+// prjnState is the state of a projection -- see layerState
+type prjnState struct {
+	Name   string
+	Syns   []leabra.Synapse
+	GScale float32
+	GInc   []float32
+	WbRecv []leabra.WtBalRecvPrjn
+}
+
+// netState is the full state of a network, for checkpoints
+type netState struct {
+	WtBalCtr int
+	Layers   []layerState
+}
+
+// SaveNetState saves the full state of given network to a gzipped gob file
+func SaveNetState(net *leabra.Network, fname string) error {
+	ns := &netState{WtBalCtr: net.WtBalCtr}
+	for _, l := range net.Layers {
+		ly := l.(leabra.LeabraLayer).AsLeabra()
+		ls := layerState{Name: ly.Nm, Neurons: ly.Neurons, Pools: ly.Pools, CosDiff: ly.CosDiff}
+		for _, p := range ly.RcvPrjns {
+			pj := p.(leabra.LeabraPrjn).AsLeabra()
+			ls.Prjns = append(ls.Prjns, prjnState{Name: pj.Name(), Syns: pj.Syns, GScale: pj.GScale, GInc: pj.GInc, WbRecv: pj.WbRecv})
+		}
+		ns.Layers = append(ns.Layers, ls)
+	}
+	f, err := os.Create(fname)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	if err := gob.NewEncoder(gz).Encode(ns); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// OpenNetState restores the full state of given network from a file saved by
+// SaveNetState -- the network must have the same structure and connectivity
+func OpenNetState(net *leabra.Network, fname string) error {
+	f, err := os.Open(fname)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	ns := &netState{}
+	if err := gob.NewDecoder(gz).Decode(ns); err != nil {
+		return err
+	}
+	if len(ns.Layers) != len(net.Layers) {
+		return fmt.Errorf("OpenNetState: %v has %d layers, network has %d", fname, len(ns.Layers), len(net.Layers))
+	}
+	for li, l := range net.Layers {
+		ly := l.(leabra.LeabraLayer).AsLeabra()
+		ls := &ns.Layers[li]
+		if ls.Name != ly.Nm || len(ls.Neurons) != len(ly.Neurons) || len(ls.Prjns) != len(ly.RcvPrjns) {
+			return fmt.Errorf("OpenNetState: layer %s in %v does not match layer %s of the network", ls.Name, fname, ly.Nm)
+		}
+		for pi, p := range ly.RcvPrjns {
+			pj := p.(leabra.LeabraPrjn).AsLeabra()
+			ps := &ls.Prjns[pi]
+			if ps.Name != pj.Name() || len(ps.Syns) != len(pj.Syns) {
+				return fmt.Errorf("OpenNetState: projection %s in %v does not match projection %s of the network", ps.Name, fname, pj.Name())
+			}
+		}
+	}
+	net.WtBalCtr = ns.WtBalCtr
+	for li, l := range net.Layers {
+		ly := l.(leabra.LeabraLayer).AsLeabra()
+		ls := &ns.Layers[li]
+		copy(ly.Neurons, ls.Neurons)
+		copy(ly.Pools, ls.Pools)
+		ly.CosDiff = ls.CosDiff
+		for pi, p := range ly.RcvPrjns {
+			pj := p.(leabra.LeabraPrjn).AsLeabra()
+			ps := &ls.Prjns[pi]
+			copy(pj.Syns, ps.Syns)
+			pj.GScale = ps.GScale
+			copy(pj.GInc, ps.GInc)
+			copy(pj.WbRecv, ps.WbRecv)
+		}
+	}
+	return nil
+}
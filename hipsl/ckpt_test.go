@@ -0,0 +1,103 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/emer/emergent/params"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// sameLogs reports the differences between the rows of given logs, except for
+// their timing columns, which differ from run to run
+func sameLogs(t *testing.T, nm string, a, b *etable.Table) {
+	t.Helper()
+	if a.Rows != b.Rows {
+		t.Errorf("%s: %d rows, want %d", nm, b.Rows, a.Rows)
+		return
+	}
+	for ci, cl := range a.ColNames {
+		if strings.Contains(cl, "MSec") {
+			continue
+		}
+		bc, err := b.ColByNameTry(cl)
+		if err != nil {
+			t.Errorf("%s: %v", nm, err)
+			continue
+		}
+		ac := a.Cols[ci]
+		for row := 0; row < a.Rows; row++ {
+			if ac.DataType() == etensor.STRING {
+				if av, bv := a.CellString(cl, row), b.CellString(cl, row); av != bv {
+					t.Errorf("%s row %d %s: %s, want %s", nm, row, cl, bv, av)
+				}
+				continue
+			}
+			_, acl := ac.RowCellSize()
+			for ci := 0; ci < acl; ci++ {
+				av, bv := ac.FloatValRowCell(row, ci), bc.FloatValRowCell(row, ci)
+				if av != bv && !(math.IsNaN(av) && math.IsNaN(bv)) {
+					t.Errorf("%s row %d %s: %g, want %g", nm, row, cl, bv, av)
+					break
+				}
+			}
+		}
+	}
+}
+
+// TestCkptResume checks that resuming from a checkpoint continues the run exactly
+// as it went on without interruption, with the settings of the checkpoint
+func TestCkptResume(t *testing.T) {
+	chdirRoot(t)
+	dir := t.TempDir()
+	pfile := filepath.Join(dir, "epcs.json") // MaxEpcs is set by the Base params
+	epcs := params.Sets{{Name: "Base", Sheets: params.Sheets{"Sim": &params.Sheet{
+		{Sel: "Sim", Params: params.Params{"Sim.MaxEpcs": "3"}},
+	}}}}
+	if err := SaveParamsSets(epcs, pfile); err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	cfg.ParamsFiles = []string{pfile}
+	cfg.MaxRuns = 1
+	cfg.TrialperEpc = 40
+	cfg.CkptInterval = 2 // the only checkpoint, at the start of epoch 2
+	cfg.Recog = true
+	cfg.Replay.On = true
+	cfg.Replay.Trials = 5
+	cfg.WtSnap.On = true
+	ss, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss.CkptDir = filepath.Join(dir, "ckpt")
+	ss.Train()
+
+	rs, err := Resume(DefaultConfig(), ss.CkptDir) // without the params file and settings
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ep := rs.TrainEnv.Ctrs().Epoch.Cur; ep != 2 {
+		t.Fatalf("resumed at epoch %d, want 2", ep)
+	}
+	if !rs.RecogOn || !rs.Replay.On || rs.Replay.Trials != 5 || !rs.WtSnap.On || rs.MaxEpcs != 3 || rs.TrialperEpc != 40 {
+		t.Errorf("settings of the checkpoint not restored: Recog %v Replay %v %d WtSnap %v MaxEpcs %d TrialperEpc %d", rs.RecogOn, rs.Replay.On, rs.Replay.Trials, rs.WtSnap.On, rs.MaxEpcs, rs.TrialperEpc)
+	}
+	rs.Train()
+
+	sameLogs(t, "TrnEpcLog", ss.TrnEpcLog, rs.TrnEpcLog)
+	sameLogs(t, "TstEpcLog", ss.TstEpcLog, rs.TstEpcLog)
+	sameLogs(t, "RunLog", ss.RunLog, rs.RunLog)
+	sameLogs(t, "ReplayLog", ss.ReplayLog, rs.ReplayLog)
+	sameLogs(t, "WtEpcLog", ss.WtEpcLog, rs.WtEpcLog)
+	if ss.RunLog.Rows != 1 || ss.TstEpcLog.Rows < 3 {
+		t.Errorf("uninterrupted run logged %d runs and %d test epochs", ss.RunLog.Rows, ss.TstEpcLog.Rows)
+	}
+}
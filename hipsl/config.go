@@ -0,0 +1,184 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"github.com/emer/etable/etable"
+	"github.com/goki/gi/gi"
+)
+
+// Config is the configuration of a new Sim made by New: the paradigm, network,
+// params and schedule of its runs.  DefaultConfig returns the defaults of the model,
+// to modify before New.
+type Config struct {
+	Paradigm     Paradigms `desc:"statistical learning paradigm to train and test on"`
+	Topology     string    `desc:"JSON or TOML file of the topology of the network (layers and projections) to use instead of the default, if not empty"`
+	ParamsFiles  []string  `desc:"JSON or TOML files of param sets to merge into the compiled-in ones, in order"`
+	ParamSet     string    `desc:"which set of *additional* parameters to use -- always applies Base and optionaly this next if set"`
+	Lesions      string    `desc:"lesions as a comma-separated list of Type:Name@When, or a preset, see ParseLesions -- none if empty"`
+	Tag          string    `desc:"extra tag string to add to any file names output from sim (e.g., weights files, log files, params)"`
+	RndSeed      int64     `desc:"master random seed, from which the seeds of each run are derived"`
+	StartRun     int       `desc:"run number to start at -- runs go from StartRun to MaxRuns-1"`
+	MaxRuns      int       `desc:"maximum number of model runs to perform"`
+	MaxEpcs      int       `desc:"maximum number of epochs to run per model run"`
+	TrialperEpc  int       `desc:"number of trials per epoch of training"`
+	NZeroStop    int       `desc:"if a positive number, training will stop after this many epochs with zero mem errors"`
+	TestInterval int       `desc:"how often to run through all the test patterns, in terms of training epochs -- can use 0 or -1 for no testing"`
+	CkptInterval int       `desc:"how often to save a checkpoint to resume from, in terms of training epochs -- 0 for never"`
+	MemThr       float64   `desc:"threshold to use for memory test -- if error proportion is below this number, it is scored as a correct trial"`
+	Recog        bool      `desc:"if true, TestAll also runs the recognition test of the studied pairs vs. lures"`
+	RecogThr     float64   `desc:"threshold of the recognition test: an item whose ECin - ECout Match is at least this is judged old"`
+	Replay       Replay    `desc:"offline replay phase between training epochs -- set On to use"`
+	WtSnap       WtSnap    `desc:"snapshots of the weights of projections after each epoch of training, logged in the WtEpcLog -- set On to use"`
+	ActRec       ActRec    `desc:"recording of unit activity to a file per run -- set On to use"`
+	NpyRec       NpyRec    `desc:"recording of unit activity of each test item, saved with the weights to a NumPy .npz file per test -- set On to use"`
+	SaveWts      bool      `desc:"save the final weights after each run"`
+	ManifestOn   bool      `desc:"save the Manifest of each run at its end, referenced by the Manifest column of the logs"`
+	LogSetParams bool      `desc:"print a record of each parameter that is set"`
+	NoGui        bool      `desc:"if true, the Sim runs with no GUI"`
+}
+
+// DefaultConfig returns the default Config of the model, run with no GUI
+func DefaultConfig() *Config {
+	ss := &Sim{}
+	ss.New()
+	cfg := &Config{}
+	cfg.Paradigm = ss.Paradigm
+	cfg.ParamSet = ss.ParamSet
+	cfg.Tag = ss.Tag
+	cfg.RndSeed = ss.RndSeed
+	cfg.StartRun = ss.StartRun
+	cfg.MaxRuns = ss.MaxRuns
+	cfg.MaxEpcs = ss.MaxEpcs
+	cfg.TrialperEpc = ss.TrialperEpc
+	cfg.NZeroStop = ss.NZeroStop
+	cfg.TestInterval = ss.TestInterval
+	cfg.CkptInterval = ss.CkptInterval
+	cfg.MemThr = ss.MemThr
+	cfg.RecogThr = ss.RecogThr
+	cfg.Replay = ss.Replay
+	cfg.WtSnap = ss.WtSnap
+	cfg.ActRec = ss.ActRec
+	cfg.NpyRec = ss.NpyRec
+	cfg.NoGui = true
+	return cfg
+}
+
+// New returns a new Sim of given config -- the DefaultConfig if nil -- with its
+// network, environments and logs configured, and initialized for its first run.
+// Train, TrainEpoch, TestAll and RunAll run it, and Logs returns its logs.
+func New(cfg *Config) (*Sim, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	ss, err := newSim(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Topology != "" {
+		if err := ss.Topology.Open(cfg.Topology); err != nil {
+			return nil, err
+		}
+		ss.SetParadigm(ss.Paradigm)
+	}
+	if cfg.Paradigm != ss.Paradigm {
+		ss.SetParadigm(cfg.Paradigm)
+	}
+	if cfg.Lesions != "" {
+		if err := ss.SetLesions(cfg.Lesions); err != nil {
+			return nil, err
+		}
+	}
+	ss.Init()
+	if err := ss.CheckPatSizes(ss.Net); err != nil { // nothing to run on
+		return nil, err
+	}
+	return ss, nil
+}
+
+// Resume returns a new Sim of given config restored from the checkpoint in given
+// directory, to continue its run -- the paradigm, topology, lesions, params, seeds
+// and runs of the checkpoint override those of the config
+func Resume(cfg *Config, dir string) (*Sim, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	ss, err := newSim(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := ss.ResumeCkpt(dir); err != nil {
+		return nil, err
+	}
+	if err := ss.CheckPatSizes(ss.Net); err != nil {
+		return nil, err
+	}
+	return ss, nil
+}
+
+// newSim returns a new Sim configured from given config, for the compiled-in
+// paradigm, but not yet initialized
+func newSim(cfg *Config) (*Sim, error) {
+	ss := &Sim{}
+	ss.New()
+	ss.ParamSet = cfg.ParamSet
+	ss.Tag = cfg.Tag
+	ss.RndSeed = cfg.RndSeed
+	ss.StartRun = cfg.StartRun
+	ss.MaxRuns = cfg.MaxRuns
+	ss.MaxEpcs = cfg.MaxEpcs
+	ss.TrialperEpc = cfg.TrialperEpc
+	ss.NZeroStop = cfg.NZeroStop
+	ss.TestInterval = cfg.TestInterval
+	ss.CkptInterval = cfg.CkptInterval
+	ss.MemThr = cfg.MemThr
+	ss.RecogThr = cfg.RecogThr
+	ss.Replay = cfg.Replay
+	ss.Replay.NoiseLays = append([]string(nil), cfg.Replay.NoiseLays...)
+	ss.Replay.Layers = append([]string(nil), cfg.Replay.Layers...)
+	ss.WtSnap = cfg.WtSnap
+	ss.WtSnap.Prjns = append([]string(nil), cfg.WtSnap.Prjns...)
+	ss.WtSnap.Prev = nil
+	ss.ActRec = cfg.ActRec
+	ss.ActRec.Layers = append([]string(nil), cfg.ActRec.Layers...)
+	ss.ActRec.Vars = append([]string(nil), cfg.ActRec.Vars...)
+	ss.ActRec.Cycles = append([]int(nil), cfg.ActRec.Cycles...)
+	ss.ActRec.Quarters = append([]int(nil), cfg.ActRec.Quarters...)
+	ss.NpyRec = cfg.NpyRec
+	ss.NpyRec.Layers = append([]string(nil), cfg.NpyRec.Layers...)
+	ss.SaveWts = cfg.SaveWts
+	ss.ManifestOn = cfg.ManifestOn
+	ss.LogSetParams = cfg.LogSetParams
+	ss.NoGui = cfg.NoGui
+	ss.Config()
+	for _, pf := range cfg.ParamsFiles {
+		if err := ss.OpenParams(gi.FileName(pf)); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Recog {
+		ss.SetRecog(true)
+	}
+	return ss, nil
+}
+
+// Logs returns the logs of the Sim by name: TrnTrlLog, TrnEpcLog, TstTrlLog,
+// TstEpcLog, TstCycLog, RSALog, ReplayLog, WtEpcLog, RunLog, RunStats and
+// SweepLog
+func (ss *Sim) Logs() map[string]*etable.Table {
+	return map[string]*etable.Table{
+		"TrnTrlLog": ss.TrnTrlLog,
+		"TrnEpcLog": ss.TrnEpcLog,
+		"TstTrlLog": ss.TstTrlLog,
+		"TstEpcLog": ss.TstEpcLog,
+		"TstCycLog": ss.TstCycLog,
+		"RSALog":    ss.RSALog,
+		"ReplayLog": ss.ReplayLog,
+		"WtEpcLog":  ss.WtEpcLog,
+		"RunLog":    ss.RunLog,
+		"RunStats":  ss.RunStats,
+		"SweepLog":  ss.SweepLog,
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/emer/emergent/patgen"
+	"github.com/emer/emergent/weights"
+	"github.com/emer/etable/etable"
+	"github.com/emer/leabra/leabra"
+	"github.com/goki/gi/gi"
+)
+
+// CppLayerNames maps the names of the layers of the C++ emergent project of
+// Schapiro et al. (2017) to those of this network, for ConvertWts -- the others
+// have the same names
+var CppLayerNames = map[string]string{
+	"EC_in":  "ECin",
+	"EC_out": "ECout",
+}
+
+// ConvertFileName returns the default name of the file converted from given
+// C++ emergent file: its name with _go before the extension, e.g., Train_pairs_go.dat
+func ConvertFileName(fname string) string {
+	ext := filepath.Ext(fname)
+	base := strings.TrimSuffix(fname, ext)
+	if ext == ".gz" {
+		ext = filepath.Ext(base) + ext
+		base = strings.TrimSuffix(fname, ext)
+	}
+	return base + "_go" + ext
+}
+
+// ConvertPats converts the C++ emergent pattern file fname to the Go layout of
+// etable, saved to outname, reporting the remapping of the shape of each tensor
+// column to w.  The C++ layout is detected by CppLayout, unless reshape forces it.
+func ConvertPats(fname, outname string, reshape bool, w io.Writer) error {
+	dt := &etable.Table{}
+	if err := dt.OpenCSV(gi.FileName(fname), etable.Tab); err != nil {
+		return err
+	}
+	if !reshape && !CppLayout(dt) {
+		return fmt.Errorf("ConvertPats: %s is not in the C++ layout -- use -reshape to reshape it anyway", fname)
+	}
+	shps := make([][]int, len(dt.Cols))
+	for ci, cl := range dt.Cols {
+		shps[ci] = cl.Shapes()[1:]
+	}
+	patgen.ReshapeCpp(dt)
+	fmt.Fprintf(w, "%s: %d patterns -> %s\n", fname, dt.Rows, outname)
+	nrs := 0
+	for ci, cl := range dt.Cols {
+		shp := cl.Shapes()[1:]
+		if fmt.Sprint(shp) == fmt.Sprint(shps[ci]) {
+			continue
+		}
+		fmt.Fprintf(w, "  %s: %v -> %v\n", dt.ColNames[ci], shps[ci], shp)
+		nrs++
+	}
+	if nrs == 0 {
+		fmt.Fprintf(w, "  no column reshaped\n")
+	}
+	return dt.SaveCSV(gi.FileName(outname), etable.Tab, etable.Headers)
+}
+
+// OpenWtsCpp reads the weights of the C++ emergent weights file fname, which is
+// gzip uncompressed if it has a .gz extension
+func OpenWtsCpp(fname string) (*weights.Network, error) {
+	fp, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	var r io.Reader = fp
+	if filepath.Ext(fname) == ".gz" {
+		gzr, err := gzip.NewReader(fp)
+		if err != nil {
+			return nil, err
+		}
+		defer gzr.Close()
+		r = gzr
+	}
+	return weights.NetReadCpp(r)
+}
+
+// ConvertWts converts the C++ emergent weights file fname to the weights of the
+// network, saved to outname as JSON (gzipped if .gz), reporting the mapping of
+// each layer and projection to w.  The C++ layers are renamed by lnames (see
+// CppLayerNames), and their units are mapped by their index, which is the same
+// in both, as C++ unit groups and units are in the same order as the pools and
+// units of Go.  A projection whose weights do not fit in the network is reported
+// and skipped, as are layers and projections not in the network, and layers not
+// in the file keep their initial weights.  The weights of connections not in the
+// network, as with random connectivity, are reported and dropped.
+func (ss *Sim) ConvertWts(fname, outname string, lnames map[string]string, w io.Writer) error {
+	nw, err := OpenWtsCpp(fname)
+	if err != nil {
+		return err
+	}
+	rename := func(nm string) string {
+		if gnm, ok := lnames[nm]; ok {
+			return gnm
+		}
+		return nm
+	}
+	ss.Net.InitWts()
+	fmt.Fprintf(w, "%s: %d layers -> %s\n", fname, len(nw.Layers), outname)
+	var errs []string
+	done := make(map[string]bool)
+	for li := range nw.Layers {
+		lw := &nw.Layers[li]
+		lnm := rename(lw.Layer)
+		if lnm != lw.Layer {
+			fmt.Fprintf(w, "  layer %s -> %s\n", lw.Layer, lnm)
+		}
+		ly, err := ss.Net.LayerByNameTry(lnm)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("layer %s is not in the network", lw.Layer))
+			continue
+		}
+		done[lnm] = true
+		lly := ly.(leabra.LeabraLayer).AsLeabra()
+		lly.SetWts(&weights.Layer{Layer: lnm, MetaData: lw.MetaData}) // ActAvg only
+		for pi := range lw.Prjns {
+			pw := &lw.Prjns[pi]
+			fnm := rename(pw.From)
+			pj, err := lly.SendNameTry(fnm)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("projection from %s to %s is not in the network", pw.From, lw.Layer))
+				continue
+			}
+			nr, ns := 0, 0
+			for _, rw := range pw.Rs {
+				if rw.Ri+1 > nr {
+					nr = rw.Ri + 1
+				}
+				for _, si := range rw.Si {
+					if si+1 > ns {
+						ns = si + 1
+					}
+				}
+			}
+			rsh, ssh := lly.Shape(), pj.SendLay().Shape()
+			if nr > rsh.Len() || ns > ssh.Len() {
+				errs = append(errs, fmt.Sprintf("%s: weights of %d x %d units do not fit %v x %v", pj.Name(), nr, ns, rsh.Shp, ssh.Shp))
+				continue
+			}
+			// random connectivity is drawn anew by Build, so may not have all the connections
+			lpj := pj.(leabra.LeabraPrjn).AsLeabra()
+			ncn, nmis := 0, 0
+			for _, rw := range pw.Rs {
+				for _, si := range rw.Si {
+					ncn++
+					if lpj.SynIdx(si, rw.Ri) < 0 {
+						nmis++
+					}
+				}
+			}
+			if err := pj.SetWts(pw); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", pj.Name(), err))
+				continue
+			}
+			fmt.Fprintf(w, "  %s: %d x %d units -> %v x %v", pj.Name(), nr, ns, rsh.Shp, ssh.Shp)
+			if nmis > 0 {
+				fmt.Fprintf(w, ": %d of %d connections not in the network, dropped", nmis, ncn)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+	for _, ly := range ss.Net.Layers {
+		if !done[ly.Name()] {
+			fmt.Fprintf(w, "  layer %s is not in the file: initial weights\n", ly.Name())
+		}
+	}
+	for _, er := range errs {
+		fmt.Fprintf(w, "  skipped: %s\n", er)
+	}
+	return ss.Net.SaveWtsJSON(gi.FileName(outname))
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/leabra/leabra"
+	"github.com/goki/gi/gi"
+)
+
+func TestConvertFileName(t *testing.T) {
+	for fn, cfn := range map[string]string{
+		"Train_pairs.dat":   "Train_pairs_go.dat",
+		"dir/hip.wts":       "dir/hip_go.wts",
+		"dir/hip.wts.gz":    "dir/hip_go.wts.gz",
+		"Train_pairs.1.dat": "Train_pairs.1_go.dat",
+	} {
+		if nm := ConvertFileName(fn); nm != cfn {
+			t.Errorf("%s: %s, want %s", fn, nm, cfn)
+		}
+	}
+}
+
+func TestConvertPats(t *testing.T) {
+	chdirRoot(t)
+	out := filepath.Join(t.TempDir(), ConvertFileName("Train_pairs.dat"))
+	var rep bytes.Buffer
+	if err := ConvertPats("Train_pairs.dat", out, false, &rep); err != nil {
+		t.Fatal(err)
+	}
+	for _, ln := range []string{"Input: [1 8 1 1] -> [1 1 8 1]", "ECout: [1 8 1 1] -> [1 1 8 1]"} {
+		if !strings.Contains(rep.String(), ln) {
+			t.Errorf("report %q does not contain %q", rep.String(), ln)
+		}
+	}
+
+	src, dt := &etable.Table{}, &etable.Table{}
+	if err := src.OpenCSV("Train_pairs.dat", etable.Tab); err != nil {
+		t.Fatal(err)
+	}
+	if err := dt.OpenCSV(gi.FileName(out), etable.Tab); err != nil {
+		t.Fatal(err)
+	}
+	if CppLayout(dt) || dt.Rows != src.Rows {
+		t.Fatalf("converted: CppLayout %v, %d rows, want %d", CppLayout(dt), dt.Rows, src.Rows)
+	}
+	for _, cl := range []string{"Input", "ECout"} { // the same units in the same order
+		sc, cc := src.ColByName(cl), dt.ColByName(cl)
+		if sc.Len() != cc.Len() {
+			t.Fatalf("%s: %d values, want %d", cl, cc.Len(), sc.Len())
+		}
+		for i := 0; i < sc.Len(); i++ {
+			if sc.FloatVal1D(i) != cc.FloatVal1D(i) {
+				t.Errorf("%s value %d: %g, want %g", cl, i, cc.FloatVal1D(i), sc.FloatVal1D(i))
+				break
+			}
+		}
+	}
+
+	if err := ConvertPats(out, out+".2", false, &rep); err == nil {
+		t.Errorf("no error converting a table in the Go layout")
+	}
+	if err := ConvertPats(out, out+".2", true, &rep); err != nil {
+		t.Errorf("reshape: %v", err)
+	}
+}
+
+// cppWts is a C++ emergent weights file for the network of Defaults, with some
+// layers and projections that are not in it or do not fit
+const cppWts = `<Fmt TEXT>
+<Name Hip>
+<Epoch 10>
+<Lay CA1>
+<acts_m_avg 0.2>
+<acts_p_avg 0.25>
+<Ug>
+<UgUn 0 >
+<Un>
+0
+<Cg 0 Fm:EC_in>
+<Cn 2>
+0 0.25
+1 0.75
+</Cn>
+</Cg>
+<Cg 1 Fm:CA3>
+<Cn 1>
+90 0.5
+</Cn>
+</Cg>
+</Un>
+</UgUn>
+<UgUn 1 >
+<Un>
+0
+<Cg 0 Fm:EC_in>
+<Cn 1>
+2 0.6
+</Cn>
+</Cg>
+<Cg 1 Fm:CA3>
+<Cn 1>
+0 0.5
+</Cn>
+</Cg>
+</Un>
+</UgUn>
+</Ug>
+</Lay>
+<Lay CA3>
+<Ug>
+<UgUn 0 >
+<Un>
+0
+<Cg 0 Fm:DG>
+<Cn 400>
+%s</Cn>
+</Cg>
+</Un>
+</UgUn>
+</Ug>
+</Lay>
+<Lay Bogus>
+<Ug>
+<UgUn 0 >
+<Un>
+0
+</Un>
+</UgUn>
+</Ug>
+</Lay>
+`
+
+func TestConvertWts(t *testing.T) {
+	chdirRoot(t)
+	ss, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dgWts strings.Builder
+	for si := 0; si < 400; si++ {
+		fmt.Fprintf(&dgWts, "%d 0.5\n", si)
+	}
+	dir := t.TempDir()
+	wts := []byte(fmt.Sprintf(cppWts, dgWts.String()))
+	fname := filepath.Join(dir, "hip.wts")
+	if err := ioutil.WriteFile(fname, wts, 0644); err != nil {
+		t.Fatal(err)
+	}
+	var gzb bytes.Buffer
+	gzw := gzip.NewWriter(&gzb)
+	gzw.Write(wts)
+	gzw.Close()
+	if err := ioutil.WriteFile(fname+".gz", gzb.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, fn := range []string{fname, fname + ".gz"} {
+		nw, err := OpenWtsCpp(fn)
+		if err != nil {
+			t.Fatalf("%s: %v", fn, err)
+		}
+		if len(nw.Layers) != 3 || nw.Layers[0].Layer != "CA1" || len(nw.Layers[0].Prjns) != 2 {
+			t.Errorf("%s: %d layers", fn, len(nw.Layers))
+		}
+	}
+
+	out := filepath.Join(dir, ConvertFileName("hip.wts"))
+	var rep bytes.Buffer
+	if err := ss.ConvertWts(fname, out, CppLayerNames, &rep); err != nil {
+		t.Fatal(err)
+	}
+	for _, ln := range []string{
+		"ECinToCA1: 2 x 3 units",
+		"skipped: CA3ToCA1: weights of 2 x 91 units do not fit",
+		"DGToCA3: 1 x 400 units",
+		"connections not in the network, dropped",
+		"skipped: layer Bogus is not in the network",
+		"layer ECout is not in the file",
+	} {
+		if !strings.Contains(rep.String(), ln) {
+			t.Errorf("report does not contain %q:\n%s", ln, rep.String())
+		}
+	}
+
+	ns, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ns.Net.OpenWtsJSON(gi.FileName(out)); err != nil {
+		t.Fatal(err)
+	}
+	ca1 := ns.Net.LayerByName("CA1").(leabra.LeabraLayer).AsLeabra()
+	pj := ca1.SendName("ECin").(leabra.LeabraPrjn).AsLeabra()
+	for _, sw := range []struct {
+		si, ri int
+		wt     float32
+	}{{0, 0, 0.25}, {1, 0, 0.75}, {2, 1, 0.6}} {
+		if wt := pj.SynVal("Wt", sw.si, sw.ri); math.Abs(float64(wt-sw.wt)) > 1e-5 {
+			t.Errorf("ECinToCA1 %d -> %d: Wt %g, want %g", sw.si, sw.ri, wt, sw.wt)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"fmt"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// GraphEnv generates a stream of items from a walk on a graph, as in the
+// community structure paradigm of Schapiro et al. (2013), simulated in
+// Schapiro et al. (2017).  The stream is either a random walk, where each
+// item is followed by one of its neighbors chosen at random, or a sequence of
+// random Hamiltonian paths that visit every node exactly once, each starting at a
+// neighbor of the end of the previous one.  As in PairEnv, each trial presents
+// the previous item at PrevAct and the current item at CurAct.
+type GraphEnv struct {
+	SeqCtrs
+	Nodes    []string        `desc:"names of the nodes -- node i is represented by unit i in the patterns"`
+	Adj      [][]int         `desc:"adjacency list: indexes of the neighbors of each node"`
+	Comm     []int           `desc:"community of each node, used for the test patterns and community-level stats"`
+	HamPaths bool            `desc:"if true, the stream is a sequence of random Hamiltonian paths through the graph -- otherwise a random walk"`
+	PrevAct  float32         `desc:"activity of the previous item in the overlap pattern -- 0 presents only the current item"`
+	CurAct   float32         `desc:"activity of the current item in the overlap pattern"`
+	NUnits   int             `desc:"number of units in the patterns -- if 0, uses number of Nodes"`
+	Prev     int             `inactive:"+" desc:"index of the previous node in the stream"`
+	Cur      int             `inactive:"+" desc:"index of the current node in the stream"`
+	Path     []int           `inactive:"+" desc:"current Hamiltonian path, if HamPaths"`
+	NoPath   bool            `inactive:"+" desc:"true if HamPaths but the graph has no Hamiltonian path, found at Init -- the stream is then a random walk"`
+	PathPos  int             `inactive:"+" desc:"position of Cur in Path"`
+	Pattern  etensor.Float32 `desc:"current pattern, applied to Input and ECout -- TrialName is the previous then current node name"`
+}
+
+// Defaults sets the 15-node graph of Schapiro et al. (2013): three communities
+// of 5 nodes, A-E, F-J and K-O, each with all nodes connected except its two
+// boundary nodes, which connect to the boundary nodes of the neighboring communities
+// so that every node has 4 neighbors.  The stream is a random walk.
+func (ev *GraphEnv) Defaults() {
+	nc := 3
+	cn := 5
+	nn := nc * cn
+	ev.Nodes = make([]string, nn)
+	ev.Comm = make([]int, nn)
+	ev.Adj = make([][]int, nn)
+	for i := 0; i < nn; i++ {
+		ev.Nodes[i] = string([]byte{byte('A' + i)})
+		ev.Comm[i] = i / cn
+	}
+	for c := 0; c < nc; c++ {
+		st := c * cn
+		for i := 0; i < cn; i++ {
+			for j := 0; j < cn; j++ {
+				if i == j || (i == 0 && j == cn-1) || (i == cn-1 && j == 0) {
+					continue // boundary nodes are not connected within community
+				}
+				ev.Adj[st+i] = append(ev.Adj[st+i], st+j)
+			}
+		}
+		nxt := ((c + 1) % nc) * cn
+		ev.Adj[st+cn-1] = append(ev.Adj[st+cn-1], nxt)
+		ev.Adj[nxt] = append(ev.Adj[nxt], st+cn-1)
+	}
+	ev.HamPaths = false
+	ev.PrevAct = 0.8
+	ev.CurAct = 1
+}
+
+func (ev *GraphEnv) Validate() error {
+	nn := len(ev.Nodes)
+	if nn == 0 {
+		return fmt.Errorf("GraphEnv: %v has no Nodes", ev.Nm)
+	}
+	if len(ev.Adj) != nn {
+		return fmt.Errorf("GraphEnv: %v Adj has %d nodes, should be %d", ev.Nm, len(ev.Adj), nn)
+	}
+	if len(ev.Comm) != 0 && len(ev.Comm) != nn {
+		return fmt.Errorf("GraphEnv: %v Comm has %d nodes, should be %d", ev.Nm, len(ev.Comm), nn)
+	}
+	if ev.NUnits > 0 && ev.NUnits < nn {
+		return fmt.Errorf("GraphEnv: %v NUnits %d < number of Nodes %d", ev.Nm, ev.NUnits, nn)
+	}
+	for i, nb := range ev.Adj {
+		if len(nb) == 0 {
+			return fmt.Errorf("GraphEnv: %v node %s has no neighbors", ev.Nm, ev.Nodes[i])
+		}
+		for _, j := range nb {
+			if j < 0 || j >= nn || j == i {
+				return fmt.Errorf("GraphEnv: %v node %s has invalid neighbor index %d", ev.Nm, ev.Nodes[i], j)
+			}
+		}
+	}
+	if ev.HamPaths && !ev.HasHamPath() {
+		return fmt.Errorf("GraphEnv: %v has HamPaths but the graph has no Hamiltonian path", ev.Nm)
+	}
+	return nil
+}
+
+// PatSize returns the number of units in the patterns
+func (ev *GraphEnv) PatSize() int {
+	if ev.NUnits > 0 {
+		return ev.NUnits
+	}
+	return len(ev.Nodes)
+}
+
+func (ev *GraphEnv) Init(run int) {
+	ev.Pattern.SetShape([]int{1, 1, ev.PatSize(), 1}, nil, nil)
+	ev.InitCtrs(run)
+	ev.Prev = -1
+	ev.Path = nil
+	ev.NoPath = ev.HamPaths && !ev.HasHamPath() // searched once, not at every step
+	ev.Cur = ev.Rand.Intn(len(ev.Nodes))        // stream starts before the first trial
+}
+
+// ItemNames returns the names of the nodes
+func (ev *GraphEnv) ItemNames() []string {
+	return ev.Nodes
+}
+
+// HamPath returns a random Hamiltonian path through the graph that starts at one
+// of given nodes, or nil if none exists
+func (ev *GraphEnv) HamPath(starts []int) []int {
+	return ev.hamPath(starts, ev.Rand.Perm)
+}
+
+// HasHamPath returns true if the graph has a Hamiltonian path
+func (ev *GraphEnv) HasHamPath() bool {
+	nn := len(ev.Nodes)
+	inOrder := func(n int) []int {
+		idx := make([]int, n)
+		for i := range idx {
+			idx[i] = i
+		}
+		return idx
+	}
+	return ev.hamPath(inOrder(nn), inOrder) != nil
+}
+
+// hamPath returns the first Hamiltonian path found by a depth-first search from
+// given start nodes, in which perm gives the order to try the neighbors of a node
+// and the start nodes, or nil if none exists
+func (ev *GraphEnv) hamPath(starts []int, perm func(n int) []int) []int {
+	nn := len(ev.Nodes)
+	visited := make([]bool, nn)
+	path := make([]int, 0, nn)
+	var extend func(n int) bool
+	extend = func(n int) bool {
+		visited[n] = true
+		path = append(path, n)
+		if len(path) == nn {
+			return true
+		}
+		for _, pi := range perm(len(ev.Adj[n])) {
+			nb := ev.Adj[n][pi]
+			if !visited[nb] && extend(nb) {
+				return true
+			}
+		}
+		visited[n] = false
+		path = path[:len(path)-1]
+		return false
+	}
+	for _, si := range perm(len(starts)) {
+		if extend(starts[si]) {
+			return path
+		}
+	}
+	return nil
+}
+
+// StepStream advances the stream to the next node.  With HamPaths, each new path
+// starts at a neighbor of the last node of the previous one, so that every step is
+// an edge of the graph -- if there is no path from any of them, the stream takes a
+// random step and tries again from there.
+func (ev *GraphEnv) StepStream() {
+	ev.Prev = ev.Cur
+	if ev.HamPaths && !ev.NoPath {
+		ev.PathPos++
+		if ev.PathPos >= len(ev.Path) {
+			ev.Path = ev.HamPath(ev.Adj[ev.Cur])
+			ev.PathPos = 0
+		}
+		if ev.Path != nil {
+			ev.Cur = ev.Path[ev.PathPos]
+			return
+		}
+	}
+	nb := ev.Adj[ev.Cur]
+	ev.Cur = nb[ev.Rand.Intn(len(nb))]
+}
+
+// SetPattern sets the Pattern and TrialName from the Prev and Cur nodes
+func (ev *GraphEnv) SetPattern() {
+	SetOverlapPattern(&ev.Pattern, ev.Prev, ev.Cur, ev.PrevAct, ev.CurAct)
+	ev.TrialName.Set(ev.Nodes[ev.Prev] + ev.Nodes[ev.Cur])
+}
+
+func (ev *GraphEnv) Step() bool {
+	ev.StepCtrs()
+	ev.StepStream()
+	ev.SetPattern()
+	return true
+}
+
+func (ev *GraphEnv) State(element string) etensor.Tensor {
+	switch element {
+	case "Input", "ECout":
+		return &ev.Pattern
+	}
+	return nil
+}
+
+// CommName returns the name of the community of given node
+func (ev *GraphEnv) CommName(n int) string {
+	if len(ev.Comm) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("C%d", ev.Comm[n])
+}
+
+// ConfigTestTable configures given table with one test pattern per node,
+// with the node's community in the Group column
+func (ev *GraphEnv) ConfigTestTable(dt *etable.Table) {
+	nn := len(ev.Nodes)
+	shp := []int{1, 1, ev.PatSize(), 1}
+	sch := etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Group", etensor.STRING, nil, nil},
+		{"Input", etensor.FLOAT32, shp, nil},
+		{"ECout", etensor.FLOAT32, shp, nil},
+	}
+	dt.SetFromSchema(sch, nn)
+	dt.SetMetaData("name", "Community Testing Patterns")
+	dt.SetMetaData("desc", "Community Testing Patterns")
+	in := dt.ColByName("Input").(*etensor.Float32)
+	out := dt.ColByName("ECout").(*etensor.Float32)
+	sz := ev.PatSize()
+	for i := 0; i < nn; i++ {
+		dt.SetCellString("Name", i, ev.Nodes[i])
+		dt.SetCellString("Group", i, ev.CommName(i))
+		in.Values[i*sz+i] = ev.CurAct
+		out.Values[i*sz+i] = ev.CurAct
+	}
+}
+
+// ConfigRecogTable configures given table with the items of the recognition test:
+// all the edges as targets, including those between communities, which the walk
+// crosses as often as any other edge, and as lures, the novel combinations of the
+// nodes of different communities that are not neighbors, which never follow each
+// other -- there are no across-pair lures.  The first node of a pair is presented
+// at PrevAct, or CurAct if PrevAct is 0.
+func (ev *GraphEnv) ConfigRecogTable(dt *etable.Table) {
+	nn := len(ev.Nodes)
+	rp := &RecogPairs{}
+	for i := 0; i < nn; i++ {
+		for j := i + 1; j < nn; j++ {
+			switch {
+			case ev.IsNeighbor(i, j) || ev.IsNeighbor(j, i):
+				rp.Target = append(rp.Target, [2]int{i, j})
+			case ev.CommName(i) != ev.CommName(j):
+				rp.Novel = append(rp.Novel, [2]int{i, j})
+			}
+		}
+	}
+	prvAct := ev.PrevAct
+	if prvAct == 0 {
+		prvAct = ev.CurAct
+	}
+	ConfigRecogTable(dt, ev.Nodes, ev.PatSize(), rp, prvAct, ev.CurAct)
+}
+
+// IsNeighbor returns true if node j is a neighbor of node i
+func (ev *GraphEnv) IsNeighbor(i, j int) bool {
+	for _, n := range ev.Adj[i] {
+		if n == j {
+			return true
+		}
+	}
+	return false
+}
+
+// Compile-time check that implements SeqEnv interface
+var _ SeqEnv = (*GraphEnv)(nil)
@@ -0,0 +1,129 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import "testing"
+
+// newGraphEnv returns a GraphEnv of the default graph, initialized with given seed
+func newGraphEnv(t *testing.T, hamPaths bool, seed int64) *GraphEnv {
+	ev := &GraphEnv{}
+	ev.Defaults()
+	ev.Nm = "TestGraphEnv"
+	ev.HamPaths = hamPaths
+	ev.Trial.Max = 100
+	if err := ev.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	ev.SetSeed(seed)
+	ev.Init(0)
+	return ev
+}
+
+func TestGraphEnvCommunities(t *testing.T) {
+	ev := newGraphEnv(t, false, 1)
+	if len(ev.Nodes) != 15 {
+		t.Fatalf("%d nodes, want 15", len(ev.Nodes))
+	}
+	ncomm := map[int]int{}
+	for i := range ev.Nodes {
+		ncomm[ev.Comm[i]]++
+		if len(ev.Adj[i]) != 4 {
+			t.Errorf("node %s has %d neighbors, want 4", ev.Nodes[i], len(ev.Adj[i]))
+		}
+		nacross := 0
+		for _, j := range ev.Adj[i] {
+			if !ev.IsNeighbor(j, i) {
+				t.Errorf("edge %s%s is not symmetric", ev.Nodes[i], ev.Nodes[j])
+			}
+			if ev.Comm[j] != ev.Comm[i] {
+				nacross++
+			}
+		}
+		// the boundary nodes, first and last of each community, have one edge out
+		want := 0
+		if i%5 == 0 || i%5 == 4 {
+			want = 1
+		}
+		if nacross != want {
+			t.Errorf("node %s has %d edges to other communities, want %d", ev.Nodes[i], nacross, want)
+		}
+	}
+	if len(ncomm) != 3 || ncomm[0] != 5 || ncomm[1] != 5 || ncomm[2] != 5 {
+		t.Errorf("community sizes: %v, want 3 of 5", ncomm)
+	}
+}
+
+func TestGraphEnvSteps(t *testing.T) {
+	for _, ham := range []bool{false, true} {
+		ev := newGraphEnv(t, ham, 2)
+		for i := 0; i < 2000; i++ {
+			ev.Step()
+			if !ev.IsNeighbor(ev.Prev, ev.Cur) {
+				t.Fatalf("HamPaths %v, step %d: %s is not an edge", ham, i, ev.TrialName.Cur)
+			}
+			for ui, v := range ev.Pattern.Values {
+				want := float32(0)
+				switch ui {
+				case ev.Prev:
+					want = ev.PrevAct
+				case ev.Cur:
+					want = ev.CurAct
+				}
+				if v != want {
+					t.Fatalf("%s: unit %d is %g, want %g", ev.TrialName.Cur, ui, v, want)
+				}
+			}
+		}
+	}
+}
+
+func TestGraphEnvHamPath(t *testing.T) {
+	ev := newGraphEnv(t, true, 3)
+	for k := 0; k < 20; k++ {
+		starts := ev.Adj[k%15]
+		path := ev.HamPath(starts)
+		if len(path) != len(ev.Nodes) {
+			t.Fatalf("path of %d nodes, want %d", len(path), len(ev.Nodes))
+		}
+		if !ev.IsNeighbor(k%15, path[0]) {
+			t.Errorf("path starts at %s, not a neighbor of %s", ev.Nodes[path[0]], ev.Nodes[k%15])
+		}
+		seen := make([]bool, len(ev.Nodes))
+		for i, n := range path {
+			if seen[n] {
+				t.Fatalf("path visits %s twice", ev.Nodes[n])
+			}
+			seen[n] = true
+			if i > 0 && !ev.IsNeighbor(path[i-1], n) {
+				t.Fatalf("path step %s%s is not an edge", ev.Nodes[path[i-1]], ev.Nodes[n])
+			}
+		}
+	}
+
+	// a star has no Hamiltonian path
+	st := &GraphEnv{Nodes: []string{"A", "B", "C", "D"}, Adj: [][]int{{1, 2, 3}, {0}, {0}, {0}}, HamPaths: true}
+	if st.HasHamPath() {
+		t.Error("star graph has a Hamiltonian path")
+	}
+	if err := st.Validate(); err == nil {
+		t.Error("Validate of HamPaths on a star graph did not fail")
+	}
+	st.HamPaths = false
+	if err := st.Validate(); err != nil {
+		t.Errorf("Validate of a random walk on a star graph: %v", err)
+	}
+	st.HamPaths = true
+	st.SetSeed(1)
+	st.Init(0)
+	if !st.NoPath {
+		t.Error("Init did not record that the star graph has no path")
+	}
+	for i := 0; i < 100; i++ {
+		st.Step()
+		if !st.IsNeighbor(st.Prev, st.Cur) {
+			t.Fatalf("step %d: %s is not an edge", i, st.TrialName.Cur)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+// Hooks are the functions through which a front-end of the Sim, such as its GUI,
+// follows the updates of its network and running state -- the updates of its logs
+// are followed by LogObservers.  Each is optional: none is set when the Sim runs
+// headless or embedded in another program.
+type Hooks struct {
+	ViewUpdated  func(train bool) `desc:"called when the view of the network is to be updated, in training if train, else testing"`
+	Reconfigured func()           `desc:"called after the network and logs are reconfigured, e.g., by SetParadigm, so that views of them are reconfigured too"`
+	Stopped      func()           `desc:"called when a run method stops running, after IsRunning is cleared"`
+}
+
+// Reconfigured calls the Reconfigured hook, if any
+func (ss *Sim) Reconfigured() {
+	if ss.Hooks.Reconfigured != nil {
+		ss.Hooks.Reconfigured()
+	}
+}
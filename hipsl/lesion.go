@@ -0,0 +1,244 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/emer/leabra/leabra"
+	"github.com/goki/ki/kit"
+)
+
+// LesionTypes are the kinds of lesion that can be applied to the network
+type LesionTypes int32
+
+//go:generate stringer -type=LesionTypes
+
+var KiT_LesionTypes = kit.Enums.AddEnum(LesionTypesN, kit.NotBitFlag, nil)
+
+func (ev LesionTypes) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *LesionTypes) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+const (
+	// SilenceLayer turns the layer off, so that it has no activity and sends nothing
+	SilenceLayer LesionTypes = iota
+
+	// ZeroWtScale sets the WtScale.Abs of the projection to 0, so that it sends nothing
+	// to the receiving layer -- e.g., CA3ToCA1 for a network with only the
+	// monosynaptic pathway (MSP), ECinToCA1 for only the trisynaptic pathway (TSP)
+	ZeroWtScale
+
+	// FreezeLrn turns off learning in the projection, which keeps sending
+	FreezeLrn
+
+	LesionTypesN
+)
+
+// LesionTimes are the phases of the simulation in which a lesion applies
+type LesionTimes int32
+
+//go:generate stringer -type=LesionTimes -trimprefix=Lesion
+
+var KiT_LesionTimes = kit.Enums.AddEnum(LesionTimesN, kit.NotBitFlag, nil)
+
+func (ev LesionTimes) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *LesionTimes) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+const (
+	// LesionTrain applies the lesion during training trials only
+	LesionTrain LesionTimes = iota
+
+	// LesionTest applies the lesion during testing trials only
+	LesionTest
+
+	// LesionBoth applies the lesion during both training and testing trials
+	LesionBoth
+
+	LesionTimesN
+)
+
+// Lesion is a lesion of a layer or projection of the network
+type Lesion struct {
+	Type    LesionTypes `desc:"type of lesion"`
+	Name    string      `desc:"name of the layer for SilenceLayer, or of the projection for ZeroWtScale and FreezeLrn, as sending + To + receiving layer, e.g., CA3ToCA1"`
+	When    LesionTimes `desc:"when the lesion applies: during Train, Test or Both"`
+	Applied bool        `view:"-" desc:"whether the lesion is currently applied to the network"`
+	Saved   float32     `view:"-" desc:"value of the WtScale.Abs of a ZeroWtScale projection before the lesion, to restore"`
+	SavedOn bool        `view:"-" desc:"value of the Learn.Learn of a FreezeLrn projection before the lesion, to restore"`
+}
+
+// String returns the lesion as Type:Name@When, as parsed by ParseLesions
+func (ls *Lesion) String() string {
+	return fmt.Sprintf("%v:%s@%v", ls.Type, ls.Name, ls.When)
+}
+
+// IsOn returns true if the lesion applies in training trials if train, else in testing trials
+func (ls *Lesion) IsOn(train bool) bool {
+	if train {
+		return ls.When != LesionTest
+	}
+	return ls.When != LesionTrain
+}
+
+// Prjn returns the lesioned projection of given network, by its Name
+func (ls *Lesion) Prjn(net *leabra.Network) (*leabra.Prjn, error) {
+	lnms := strings.SplitN(ls.Name, "To", 2)
+	if len(lnms) != 2 {
+		return nil, fmt.Errorf("Lesion %v: projection Name must be sending + To + receiving layer, e.g., CA3ToCA1", ls)
+	}
+	rly, err := net.LayerByNameTry(lnms[1])
+	if err != nil {
+		return nil, fmt.Errorf("Lesion %v: %v", ls, err)
+	}
+	pj, err := rly.(leabra.LeabraLayer).AsLeabra().SendNameTry(lnms[0])
+	if err != nil {
+		return nil, fmt.Errorf("Lesion %v: %v", ls, err)
+	}
+	return pj.(leabra.LeabraPrjn).AsLeabra(), nil
+}
+
+// Set applies the lesion to given network if on, else restores it, if not already so
+func (ls *Lesion) Set(net *leabra.Network, on bool) error {
+	if on == ls.Applied {
+		return nil
+	}
+	if ls.Type == SilenceLayer {
+		ly, err := net.LayerByNameTry(ls.Name)
+		if err != nil {
+			return fmt.Errorf("Lesion %v: %v", ls, err)
+		}
+		ly.SetOff(on)
+		if on {
+			ly.(leabra.LeabraLayer).InitActs() // remains silent from now on
+		}
+		ls.Applied = on
+		return nil
+	}
+	pj, err := ls.Prjn(net)
+	if err != nil {
+		return err
+	}
+	switch {
+	case ls.Type == ZeroWtScale && on:
+		ls.Saved = pj.WtScale.Abs
+		pj.WtScale.Abs = 0
+	case ls.Type == ZeroWtScale:
+		pj.WtScale.Abs = ls.Saved
+	case ls.Type == FreezeLrn && on:
+		ls.SavedOn = pj.Learn.Learn
+		pj.Learn.Learn = false
+	case ls.Type == FreezeLrn:
+		pj.Learn.Learn = ls.SavedOn
+	}
+	ls.Applied = on
+	return nil
+}
+
+// Lesions are the lesions applied to the network
+type Lesions []Lesion
+
+// LesionPresets are named lists of lesions, which can be used in place of the
+// list in ParseLesions
+var LesionPresets = map[string]string{
+	"MSPonly": "ZeroWtScale:CA3ToCA1@Both",
+	"TSPonly": "ZeroWtScale:ECinToCA1@Both",
+}
+
+// ParseLesions returns the lesions from a comma-separated list of Type:Name@When,
+// e.g., ZeroWtScale:CA3ToCA1@Both,SilenceLayer:DG@Test -- or the name of one of
+// the LesionPresets.  @When can be omitted for Both.
+func ParseLesions(spec string) (Lesions, error) {
+	if ps, ok := LesionPresets[spec]; ok {
+		spec = ps
+	}
+	var lss Lesions
+	for _, lst := range strings.Split(spec, ",") {
+		lst = strings.TrimSpace(lst)
+		if lst == "" {
+			continue
+		}
+		ls := Lesion{When: LesionBoth}
+		tnm := strings.SplitN(lst, ":", 2)
+		if len(tnm) != 2 {
+			return nil, fmt.Errorf("ParseLesions: %s is not of the form Type:Name@When", lst)
+		}
+		if err := ls.Type.FromString(tnm[0]); err != nil {
+			return nil, fmt.Errorf("ParseLesions: %v", err)
+		}
+		ls.Name = tnm[1]
+		if at := strings.LastIndex(tnm[1], "@"); at >= 0 {
+			ls.Name = tnm[1][:at]
+			if err := ls.When.FromString(tnm[1][at+1:]); err != nil {
+				return nil, fmt.Errorf("ParseLesions: %v", err)
+			}
+		}
+		lss = append(lss, ls)
+	}
+	return lss, nil
+}
+
+// String returns the lesions as a comma-separated list of Type:Name@When, as
+// parsed by ParseLesions -- empty if none
+func (lss Lesions) String() string {
+	strs := make([]string, len(lss))
+	for i := range lss {
+		strs[i] = lss[i].String()
+	}
+	return strings.Join(strs, ",")
+}
+
+// Init validates the lesions for given network, in which none of them are
+// applied yet, e.g., newly built
+func (lss Lesions) Init(net *leabra.Network) error {
+	for i := range lss {
+		ls := &lss[i]
+		ls.Applied = false
+		if ls.Type == SilenceLayer {
+			if _, err := net.LayerByNameTry(ls.Name); err != nil {
+				return fmt.Errorf("Lesion %v: %v", ls, err)
+			}
+			continue
+		}
+		if _, err := ls.Prjn(net); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Apply applies the lesions that are on in training trials if train, else in
+// testing trials, and restores the others
+func (lss Lesions) Apply(net *leabra.Network, train bool) error {
+	for i := range lss {
+		ls := &lss[i]
+		if err := ls.Set(net, ls.IsOn(train)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Restore restores all the lesioned layers and projections of given network
+func (lss Lesions) Restore(net *leabra.Network) error {
+	for i := range lss {
+		if err := lss[i].Set(net, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WtScale returns given WtScale.Abs for the projection of given name, or 0 if
+// it is lesioned by ZeroWtScale in training trials if train, else in testing trials
+func (lss Lesions) WtScale(pjnm string, train bool, abs float32) float32 {
+	for i := range lss {
+		ls := &lss[i]
+		if ls.Type == ZeroWtScale && ls.Name == pjnm && ls.IsOn(train) {
+			return 0
+		}
+	}
+	return abs
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import "testing"
+
+func TestParseLesions(t *testing.T) {
+	lss, err := ParseLesions("ZeroWtScale:CA3ToCA1@Both, SilenceLayer:DG@Test,FreezeLrn:ECinToCA3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Lesions{
+		{Type: ZeroWtScale, Name: "CA3ToCA1", When: LesionBoth},
+		{Type: SilenceLayer, Name: "DG", When: LesionTest},
+		{Type: FreezeLrn, Name: "ECinToCA3", When: LesionBoth},
+	}
+	if len(lss) != len(want) {
+		t.Fatalf("got %d lesions, want %d", len(lss), len(want))
+	}
+	for i := range want {
+		if lss[i] != want[i] {
+			t.Errorf("lesion %d: got %v, want %v", i, &lss[i], &want[i])
+		}
+	}
+	if s := lss.String(); s != "ZeroWtScale:CA3ToCA1@Both,SilenceLayer:DG@Test,FreezeLrn:ECinToCA3@Both" {
+		t.Errorf("String: %s", s)
+	}
+
+	lss, err = ParseLesions("MSPonly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lss) != 1 || lss[0].Type != ZeroWtScale || lss[0].Name != "CA3ToCA1" {
+		t.Errorf("MSPonly preset: %v", lss)
+	}
+
+	if lss, err = ParseLesions(""); err != nil || len(lss) != 0 {
+		t.Errorf("empty spec: %v, %v", lss, err)
+	}
+	for _, spec := range []string{"DG", "Silence:DG", "SilenceLayer:DG@Never"} {
+		if _, err := ParseLesions(spec); err == nil {
+			t.Errorf("ParseLesions(%q) did not fail", spec)
+		}
+	}
+}
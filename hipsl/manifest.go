@@ -0,0 +1,251 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+
+	"github.com/emer/emergent/params"
+	"github.com/emer/leabra/hip"
+	"github.com/emer/leabra/leabra"
+)
+
+// ManifestFile is an input file of a run, with its checksum
+type ManifestFile struct {
+	Path   string `desc:"absolute path of the file"`
+	SHA256 string `desc:"SHA-256 checksum of the contents of the file, in hex"`
+}
+
+// ManifestModule is a Go module the simulation was built from
+type ManifestModule struct {
+	Path    string `desc:"module path"`
+	Version string `desc:"module version"`
+	Sum     string `json:",omitempty" desc:"checksum of the module, as in go.sum"`
+}
+
+// ManifestPrjn is the values of the params of a projection
+type ManifestPrjn struct {
+	Name    string                `desc:"name of the projection, as sending + To + receiving layer"`
+	WtInit  leabra.WtInitParams   `desc:"initial random weight distribution"`
+	WtScale leabra.WtScaleParams  `desc:"weight scaling -- note that the Abs of the ECin -> CA1 and CA3 -> CA1 projections is set by the Theta schedule during each trial"`
+	Learn   leabra.LearnSynParams `desc:"synaptic-level learning params"`
+	CHL     *hip.CHLParams        `json:",omitempty" desc:"CHL learning params of a CHL projection"`
+}
+
+// ManifestLayer is the values of the params of a layer and its receiving projections
+type ManifestLayer struct {
+	Name  string                 `desc:"name of the layer"`
+	Act   leabra.ActParams       `desc:"activation params"`
+	Inhib leabra.InhibParams     `desc:"inhibition params"`
+	Learn leabra.LearnNeurParams `desc:"neuron-level learning params"`
+	Prjns []ManifestPrjn         `desc:"params of the receiving projections"`
+}
+
+// Manifest is the provenance record of one run, saved as JSON by SaveManifest
+// at the end of the run: everything needed to know how its results were produced.
+// The logs reference it by its file name in their Manifest column.
+type Manifest struct {
+	File         string            `desc:"file name of the manifest, in the Manifest column of the logs of the run"`
+	Net          string            `desc:"name of the network"`
+	Run          int               `desc:"run number"`
+	ParamSet     string            `desc:"ParamSet applied on top of Base"`
+	Tag          string            `desc:"Tag of the run"`
+	Paradigm     string            `desc:"statistical learning paradigm"`
+	Lesions      string            `desc:"lesions of the network, as parsed by ParseLesions"`
+	RndSeed      int64             `desc:"master random seed"`
+	Seeds        RunSeeds          `desc:"seeds of the run, derived from RndSeed and the run number"`
+	Resumed      string            `json:",omitempty" desc:"checkpoint directory the run was resumed from, if any"`
+	Start        time.Time         `desc:"time the run started"`
+	End          time.Time         `desc:"time the run ended"`
+	MaxEpcs      int               `desc:"maximum number of epochs"`
+	TrialperEpc  int               `desc:"number of trials per epoch"`
+	NZeroStop    int               `desc:"number of epochs with zero mem errors to stop after"`
+	TestInterval int               `desc:"testing interval in epochs"`
+	MemThr       float64           `desc:"threshold of the memory test"`
+	RecogOn      bool              `desc:"whether the recognition test was run"`
+	RecogThr     float64           `desc:"threshold of the recognition test"`
+	Replay       Replay            `desc:"settings of the replay phases"`
+	Theta        ThetaSched        `desc:"schedule of the quarters of the theta cycle"`
+	Env          json.RawMessage   `desc:"config of the training environment"`
+	Topology     Topology          `desc:"topology of the network"`
+	Sets         params.Sets       `desc:"param sets applied, in order: Base then ParamSet"`
+	Layers       []ManifestLayer   `desc:"values of the params of all layers and projections after SetParams"`
+	PatFiles     []ManifestFile    `desc:"pattern files"`
+	ParamsFiles  []ManifestFile    `desc:"params files merged into the compiled-in param sets"`
+	GoVersion    string            `desc:"version of Go the simulation was built with"`
+	Main         ManifestModule    `desc:"main module of the simulation"`
+	Deps         []ManifestModule  `desc:"module dependencies of the build"`
+	Args         []string          `desc:"command line arguments"`
+	Flags        map[string]string `desc:"values of all the command line flags, if parsed"`
+	Logs         []string          `desc:"log files of the run, which reference the manifest"`
+}
+
+// ManifestFileName returns the file name of the manifest of given run
+func (ss *Sim) ManifestFileName(run int) string {
+	return ss.Net.Nm + "_" + ss.RunName() + fmt.Sprintf("_run%d_manifest.json", run)
+}
+
+// RecordParams records the values of the params of given network and the sets
+// applied by SetParams -- call right after SetParams
+func (mf *Manifest) RecordParams(net *leabra.Network, sets params.Sets) {
+	mf.Sets = sets
+	mf.Layers = nil
+	for _, ly := range net.Layers {
+		lly := ly.(leabra.LeabraLayer).AsLeabra()
+		ml := ManifestLayer{Name: lly.Nm, Act: lly.Act, Inhib: lly.Inhib, Learn: lly.Learn}
+		for _, pj := range lly.RcvPrjns {
+			lpj := pj.(leabra.LeabraPrjn).AsLeabra()
+			mp := ManifestPrjn{Name: lpj.Name(), WtInit: lpj.WtInit, WtScale: lpj.WtScale, Learn: lpj.Learn}
+			if cpj, ok := pj.(*hip.CHLPrjn); ok {
+				chl := cpj.CHL
+				mp.CHL = &chl
+			}
+			ml.Prjns = append(ml.Prjns, mp)
+		}
+		mf.Layers = append(mf.Layers, ml)
+	}
+}
+
+// AppliedSets returns the param sets applied by SetParams: Base and the ParamSet
+func (ss *Sim) AppliedSets() params.Sets {
+	var sets params.Sets
+	if ps, err := ss.Params.SetByNameTry("Base"); err == nil {
+		sets = append(sets, ps)
+	}
+	if ss.ParamSet != "" && ss.ParamSet != "Base" {
+		if ps, err := ss.Params.SetByNameTry(ss.ParamSet); err == nil {
+			sets = append(sets, ps)
+		}
+	}
+	return sets
+}
+
+// StartManifest starts the Manifest of the current run -- called by NewRun
+func (ss *Sim) StartManifest() {
+	mf := &ss.Manifest
+	run := ss.TrainEnv.Ctrs().Run.Cur
+	mf.File = ss.ManifestFileName(run)
+	mf.Net = ss.Net.Nm
+	mf.Run = run
+	mf.ParamSet = ss.ParamSet
+	mf.Tag = ss.Tag
+	mf.Paradigm = ss.Paradigm.String()
+	mf.Lesions = ss.Lesions.String()
+	mf.RndSeed = ss.RndSeed
+	mf.Seeds = ss.Seeds
+	mf.Resumed = ""
+	mf.Start = time.Now()
+	mf.End = time.Time{}
+}
+
+// ManifestRef returns the reference to the Manifest of the current run in the
+// Manifest column of the logs: its File if ManifestOn, else empty
+func (ss *Sim) ManifestRef() string {
+	if !ss.ManifestOn {
+		return ""
+	}
+	return ss.Manifest.File
+}
+
+// SaveManifest completes the Manifest of the current run and saves it to its
+// File -- called by RunEnd if ManifestOn
+func (ss *Sim) SaveManifest() error {
+	mf := &ss.Manifest
+	mf.End = time.Now()
+	mf.MaxEpcs = ss.MaxEpcs
+	mf.TrialperEpc = ss.TrialperEpc
+	mf.NZeroStop = ss.NZeroStop
+	mf.TestInterval = ss.TestInterval
+	mf.MemThr = ss.MemThr
+	mf.RecogOn = ss.RecogOn
+	mf.RecogThr = ss.RecogThr
+	mf.Replay = ss.Replay
+	mf.Theta = ss.Theta
+	mf.Topology = ss.Topology
+	var err error
+	if mf.Env, err = MarshalSeqEnv(ss.TrainEnv); err != nil {
+		return err
+	}
+	mf.PatFiles = nil
+	for _, fnm := range ss.PatFiles {
+		mfl, err := ChecksumFile(fnm)
+		if err != nil {
+			return err
+		}
+		mf.PatFiles = append(mf.PatFiles, mfl)
+	}
+	mf.ParamsFiles = nil
+	for _, fnm := range ss.ParamsFiles {
+		mfl, err := ChecksumFile(fnm)
+		if err != nil {
+			return err
+		}
+		mf.ParamsFiles = append(mf.ParamsFiles, mfl)
+	}
+	mf.Logs = append([]string{}, ss.LogFiles...)
+	if ss.ActRec.On {
+		mf.Logs = append(mf.Logs, filepath.Join(ss.ActRec.Dir, ss.ActRecFileName(mf.Run)))
+	}
+	mf.BuildInfo()
+	mf.Args = os.Args
+	mf.Flags = nil
+	if flag.Parsed() {
+		mf.Flags = make(map[string]string)
+		flag.VisitAll(func(fl *flag.Flag) {
+			mf.Flags[fl.Name] = fl.Value.String()
+		})
+	}
+	b, err := json.MarshalIndent(mf, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(mf.File, b, 0644)
+}
+
+// BuildInfo records the versions of Go and of the modules the simulation was built with
+func (mf *Manifest) BuildInfo() {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	mf.GoVersion = bi.GoVersion
+	mf.Main = ManifestModule{Path: bi.Main.Path, Version: bi.Main.Version, Sum: bi.Main.Sum}
+	mf.Deps = nil
+	for _, dp := range bi.Deps {
+		if dp.Replace != nil {
+			dp = dp.Replace
+		}
+		mf.Deps = append(mf.Deps, ManifestModule{Path: dp.Path, Version: dp.Version, Sum: dp.Sum})
+	}
+}
+
+// ChecksumFile returns the SHA-256 checksum of given file, with its absolute path
+func ChecksumFile(fnm string) (ManifestFile, error) {
+	mfl := ManifestFile{Path: fnm}
+	if afn, err := filepath.Abs(fnm); err == nil {
+		mfl.Path = afn
+	}
+	f, err := os.Open(fnm)
+	if err != nil {
+		return mfl, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return mfl, err
+	}
+	mfl.SHA256 = hex.EncodeToString(h.Sum(nil))
+	return mfl, nil
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestChecksumFile(t *testing.T) {
+	fnm := filepath.Join(t.TempDir(), "abc.dat")
+	if err := ioutil.WriteFile(fnm, []byte("abc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mfl, err := ChecksumFile(fnm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mfl.SHA256 != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Errorf("SHA256 of abc: %s", mfl.SHA256)
+	}
+	if !filepath.IsAbs(mfl.Path) {
+		t.Errorf("Path not absolute: %s", mfl.Path)
+	}
+	if _, err := ChecksumFile(fnm + ".none"); err == nil {
+		t.Errorf("no error for a missing file")
+	}
+}
+
+func TestSaveManifest(t *testing.T) {
+	chdirRoot(t)
+	cfg := DefaultConfig()
+	cfg.StartRun = 1
+	cfg.MaxRuns = 2
+	cfg.Tag = "mf"
+	cfg.ManifestOn = true
+	ss, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ss.ManifestRef() != ss.ManifestFileName(1) {
+		t.Errorf("ManifestRef: %s, want %s", ss.ManifestRef(), ss.ManifestFileName(1))
+	}
+	ss.Manifest.File = filepath.Join(t.TempDir(), ss.Manifest.File)
+	if err := ss.SaveManifest(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(ss.Manifest.File)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mf Manifest
+	if err := json.Unmarshal(b, &mf); err != nil {
+		t.Fatal(err)
+	}
+
+	if mf.Run != 1 || mf.Tag != "mf" || mf.Paradigm != ss.Paradigm.String() || mf.RndSeed != ss.RndSeed || mf.Seeds != ss.Seeds {
+		t.Errorf("run fields: Run %d Tag %s Paradigm %s RndSeed %d Seeds %v", mf.Run, mf.Tag, mf.Paradigm, mf.RndSeed, mf.Seeds)
+	}
+	if mf.MaxEpcs != ss.MaxEpcs || mf.TrialperEpc != ss.TrialperEpc || mf.Theta != ss.Theta {
+		t.Errorf("settings: MaxEpcs %d TrialperEpc %d Theta %v", mf.MaxEpcs, mf.TrialperEpc, mf.Theta)
+	}
+	if len(mf.Topology.Layers) != len(ss.Topology.Layers) || len(mf.Topology.Prjns) != len(ss.Topology.Prjns) {
+		t.Errorf("Topology: %v", mf.Topology)
+	}
+	if len(mf.Sets) == 0 || mf.Sets[0].Name != "Base" {
+		t.Errorf("Sets: %v", mf.Sets)
+	}
+	if len(mf.Layers) != ss.Net.NLayers() {
+		t.Errorf("%d layers, want %d", len(mf.Layers), ss.Net.NLayers())
+	}
+	if len(mf.Env) == 0 {
+		t.Errorf("no Env")
+	}
+
+	if len(ss.PatFiles) == 0 || len(mf.PatFiles) != len(ss.PatFiles) {
+		t.Fatalf("%d PatFiles, want %d", len(mf.PatFiles), len(ss.PatFiles))
+	}
+	for i, fnm := range ss.PatFiles {
+		mfl, err := ChecksumFile(fnm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mf.PatFiles[i] != mfl || len(mfl.SHA256) != 64 {
+			t.Errorf("PatFiles %d: %v, want %v", i, mf.PatFiles[i], mfl)
+		}
+	}
+}
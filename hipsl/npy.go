@@ -0,0 +1,405 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/leabra/leabra"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////
+// 		NumPy files
+
+// NpyDtype returns the NumPy dtype of the values of given tensor, e.g., <f4 for
+// float32 -- strings are unicode of the length of the longest one, and the types
+// with no NumPy equivalent here are saved as float64
+func NpyDtype(tsr etensor.Tensor) string {
+	switch t := tsr.(type) {
+	case *etensor.Float32:
+		return "<f4"
+	case *etensor.Float64:
+		return "<f8"
+	case *etensor.Int64, *etensor.Int:
+		return "<i8"
+	case *etensor.Int32:
+		return "<i4"
+	case *etensor.Uint8:
+		return "|u1"
+	case *etensor.Bits:
+		return "|b1"
+	case *etensor.String:
+		n := 1
+		for _, s := range t.Values {
+			if l := utf8.RuneCountInString(s); l > n {
+				n = l
+			}
+		}
+		return fmt.Sprintf("<U%d", n)
+	}
+	return "<f8"
+}
+
+// WriteNpy writes given tensor to w in the NumPy .npy format, in row-major order,
+// e.g., to load with numpy.load
+func WriteNpy(w io.Writer, tsr etensor.Tensor) error {
+	dtype := NpyDtype(tsr)
+	shp := make([]string, tsr.NumDims())
+	for i, n := range tsr.Shapes() {
+		shp[i] = fmt.Sprint(n)
+	}
+	shape := strings.Join(shp, ", ")
+	if len(shp) == 1 {
+		shape += ","
+	}
+	hdr := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': (%s), }", dtype, shape)
+	// magic, version and header length, then the header padded to 64 bytes with a newline
+	pad := 64 - (10+len(hdr)+1)%64
+	hdr += strings.Repeat(" ", pad%64) + "\n"
+	bw := bufio.NewWriter(w)
+	bw.WriteString("\x93NUMPY\x01\x00")
+	binary.Write(bw, binary.LittleEndian, uint16(len(hdr)))
+	bw.WriteString(hdr)
+	var err error
+	switch t := tsr.(type) {
+	case *etensor.Float32:
+		err = binary.Write(bw, binary.LittleEndian, t.Values)
+	case *etensor.Float64:
+		err = binary.Write(bw, binary.LittleEndian, t.Values)
+	case *etensor.Int64:
+		err = binary.Write(bw, binary.LittleEndian, t.Values)
+	case *etensor.Int32:
+		err = binary.Write(bw, binary.LittleEndian, t.Values)
+	case *etensor.Uint8:
+		_, err = bw.Write(t.Values)
+	case *etensor.Int:
+		for _, v := range t.Values {
+			if err = binary.Write(bw, binary.LittleEndian, int64(v)); err != nil {
+				break
+			}
+		}
+	case *etensor.Bits:
+		for i := 0; i < t.Len(); i++ {
+			b := byte(0)
+			if t.Value1D(i) {
+				b = 1
+			}
+			if err = bw.WriteByte(b); err != nil {
+				break
+			}
+		}
+	case *etensor.String:
+		var n int
+		fmt.Sscanf(dtype, "<U%d", &n)
+		buf := make([]uint32, n)
+		for _, s := range t.Values {
+			i := 0
+			for _, r := range s {
+				buf[i] = uint32(r)
+				i++
+			}
+			for ; i < n; i++ {
+				buf[i] = 0
+			}
+			if err = binary.Write(bw, binary.LittleEndian, buf); err != nil {
+				break
+			}
+		}
+	default:
+		var vals []float64
+		tsr.Floats(&vals)
+		err = binary.Write(bw, binary.LittleEndian, vals)
+	}
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// SaveNpy saves given tensor to a NumPy .npy file of given name
+func SaveNpy(fname string, tsr etensor.Tensor) error {
+	f, err := os.Create(fname)
+	if err != nil {
+		return err
+	}
+	err = WriteNpy(f, tsr)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// NpzFile is a NumPy .npz file being written: a zip archive of named .npy arrays,
+// compressed as by numpy.savez_compressed.  It must be closed to be complete.
+type NpzFile struct {
+	Name string      `desc:"name of the file"`
+	File *os.File    `desc:"the file"`
+	Zip  *zip.Writer `desc:"writer of the zip archive"`
+}
+
+// CreateNpz creates the NumPy .npz file of given name
+func CreateNpz(fname string) (*NpzFile, error) {
+	f, err := os.Create(fname)
+	if err != nil {
+		return nil, err
+	}
+	return &NpzFile{Name: fname, File: f, Zip: zip.NewWriter(f)}, nil
+}
+
+// Add adds given tensor as the array of given name
+func (nz *NpzFile) Add(name string, tsr etensor.Tensor) error {
+	w, err := nz.Zip.CreateHeader(&zip.FileHeader{Name: name + ".npy", Method: zip.Deflate})
+	if err != nil {
+		return err
+	}
+	if err := WriteNpy(w, tsr); err != nil {
+		return fmt.Errorf("NpzFile: %v: %v: %v", nz.Name, name, err)
+	}
+	return nil
+}
+
+// AddStrings adds given strings as the 1D unicode array of given name
+func (nz *NpzFile) AddStrings(name string, strs []string) error {
+	tsr := etensor.NewString([]int{len(strs)}, nil, nil)
+	copy(tsr.Values, strs)
+	return nz.Add(name, tsr)
+}
+
+// AddInts adds given ints as the 1D int64 array of given name
+func (nz *NpzFile) AddInts(name string, vals []int) error {
+	vs := make([]int64, len(vals))
+	for i, v := range vals {
+		vs[i] = int64(v)
+	}
+	return nz.Add(name, etensor.NewInt64Shape(etensor.NewShape([]int{len(vs)}, nil, nil), vs))
+}
+
+// Close writes the directory of the archive and closes the file
+func (nz *NpzFile) Close() error {
+	err := nz.Zip.Close()
+	if cerr := nz.File.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// PrjnWts returns the recv x send matrix of the weights of given projection, NaN
+// where there is no synapse, e.g., for the missing connections of a UnifRnd
+// projection, and the recv x send mask of its synapses
+func PrjnWts(pj *leabra.Prjn) (*etensor.Float32, *etensor.Bits) {
+	nr := pj.Recv.Shape().Len()
+	ns := pj.Send.Shape().Len()
+	wts := etensor.NewFloat32([]int{nr, ns}, nil, []string{"Recv", "Send"})
+	mask := etensor.NewBits([]int{nr, ns}, nil, []string{"Recv", "Send"})
+	for i := range wts.Values {
+		wts.Values[i] = float32(math.NaN())
+	}
+	for si := 0; si < ns; si++ {
+		nc := int(pj.SConN[si])
+		st := int(pj.SConIdxSt[si])
+		for ci := 0; ci < nc; ci++ {
+			ri := int(pj.SConIdx[st+ci])
+			wts.Values[ri*ns+si] = pj.Syns[st+ci].Wt
+			mask.Set1D(ri*ns+si, true)
+		}
+	}
+	return wts, mask
+}
+
+// AddPrjnWts adds the weights of all the projections of given network, as the
+// recv x send arrays named after them, e.g., CA3ToCA1, with their masks, e.g.,
+// CA3ToCA1_mask, and returns their names
+func (nz *NpzFile) AddPrjnWts(net *leabra.Network) ([]string, error) {
+	var pnms []string
+	for _, ly := range net.Layers {
+		for _, p := range ly.(leabra.LeabraLayer).AsLeabra().RcvPrjns {
+			pj := p.(leabra.LeabraPrjn).AsLeabra()
+			wts, mask := PrjnWts(pj)
+			if err := nz.Add(pj.Name(), wts); err != nil {
+				return nil, err
+			}
+			if err := nz.Add(pj.Name()+"_mask", mask); err != nil {
+				return nil, err
+			}
+			pnms = append(pnms, pj.Name())
+		}
+	}
+	return pnms, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////
+// 		NpyRec
+
+// NpyRec records the Var activity of the units of the Layers at each cycle of each
+// test item of TestAll, for SaveNpz to save it to a NumPy .npz file, with the
+// weights of the projections: an item x unit x cycle array per layer.  If Save,
+// it is saved at the end of each TestAll to a file per run and epoch, in Dir.
+type NpyRec struct {
+	On      bool                 `desc:"whether to record the activity of each test item"`
+	Layers  []string             `desc:"names of the layers to record"`
+	Var     string               `desc:"unit variable to record, e.g., Act"`
+	Wts     bool                 `desc:"also save the weight matrices of the projections"`
+	Save    bool                 `desc:"save the activity of each TestAll, with the weights if Wts, to a file named after the run and epoch, in Dir"`
+	Dir     string               `desc:"directory to write the .npz files to -- created if it does not exist"`
+	NCycles int                  `view:"-" desc:"number of cycles recorded for each item, from the testing Theta schedule"`
+	Items   []string             `view:"-" desc:"names of the items recorded since the last Reset"`
+	Tests   []string             `view:"-" desc:"name of the test of each of the Items"`
+	Acts    map[string][]float32 `view:"-" desc:"recorded activity of each layer, of item x unit x cycle, NaN for cycles not run"`
+	Vals    []float32            `view:"-" desc:"temp slice for holding unit values"`
+}
+
+// Defaults records Act of all the layers of the hippocampus, with the weights,
+// into the npy directory
+func (nr *NpyRec) Defaults() {
+	nr.Layers = []string{"ECin", "ECout", "DG", "CA3", "CA1"}
+	nr.Var = "Act"
+	nr.Wts = true
+	nr.Save = true
+	nr.Dir = "npy"
+}
+
+// Reset clears the recorded items, to record items of given number of cycles
+func (nr *NpyRec) Reset(ncyc int) {
+	nr.NCycles = ncyc
+	nr.Items = nil
+	nr.Tests = nil
+	nr.Acts = make(map[string][]float32)
+}
+
+// Record records the current Var activity of the Layers of given network at given
+// cycle of the trial of the test item of given name, in given test -- a new item
+// starts at cycle 0
+func (nr *NpyRec) Record(net emer.Network, item, test string, cyc int) {
+	if nr.Acts == nil || cyc >= nr.NCycles {
+		return
+	}
+	if cyc == 0 {
+		nr.Items = append(nr.Items, item)
+		nr.Tests = append(nr.Tests, test)
+		for _, lnm := range nr.Layers {
+			n := net.LayerByName(lnm).Shape().Len() * nr.NCycles
+			acts := nr.Acts[lnm]
+			for i := 0; i < n; i++ {
+				acts = append(acts, float32(math.NaN()))
+			}
+			nr.Acts[lnm] = acts
+		}
+	}
+	if len(nr.Items) == 0 {
+		return
+	}
+	it := len(nr.Items) - 1
+	for _, lnm := range nr.Layers {
+		ly := net.LayerByName(lnm)
+		nr.Vals = nr.Vals[:0] // UnitVals only grows it
+		if err := ly.UnitVals(&nr.Vals, nr.Var); err != nil {
+			continue
+		}
+		nu := len(nr.Vals)
+		acts := nr.Acts[lnm][it*nu*nr.NCycles:]
+		for ui, v := range nr.Vals {
+			acts[ui*nr.NCycles+cyc] = v
+		}
+	}
+}
+
+// NpzFileName returns the name of the .npz file of given run and epoch, in the
+// NpyRec Dir
+func (ss *Sim) NpzFileName(run, epc int) string {
+	return ss.Net.Nm + "_" + ss.RunName() + fmt.Sprintf("_run%d_epc%d.npz", run, epc)
+}
+
+// SaveNpz saves the activity recorded by the NpyRec, and the weights of the
+// projections if its Wts, to a NumPy .npz file of given name, e.g., to load with
+// numpy.load.  The activity of each layer is the item x unit x cycle array named
+// after the layer, e.g., CA3, whose units are of the shape of the layer_shape
+// array, e.g., CA3_shape, and the weights of each projection are the recv x send
+// array named after it, e.g., CA3ToCA1, NaN where there is no synapse, as shown by
+// its mask, e.g., CA3ToCA1_mask.  The metadata are the layers, items, tests (of
+// each item), prjns, run and epoch arrays.
+func (ss *Sim) SaveNpz(fname string) error {
+	if dir := filepath.Dir(fname); dir != "." {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return err
+		}
+	}
+	nz, err := CreateNpz(fname)
+	if err != nil {
+		return err
+	}
+	err = ss.addNpz(nz)
+	if cerr := nz.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// addNpz adds the arrays of SaveNpz to given file
+func (ss *Sim) addNpz(nz *NpzFile) error {
+	nr := &ss.NpyRec
+	trn := ss.TrainEnv.Ctrs()
+	var lays []string
+	for _, lnm := range nr.Layers {
+		acts, ok := nr.Acts[lnm]
+		if !ok {
+			continue
+		}
+		ly := ss.Net.LayerByName(lnm)
+		nu := ly.Shape().Len()
+		tsr := etensor.NewFloat32Shape(etensor.NewShape([]int{len(nr.Items), nu, nr.NCycles}, nil, []string{"Item", "Unit", "Cycle"}), acts)
+		if err := nz.Add(lnm, tsr); err != nil {
+			return err
+		}
+		if err := nz.AddInts(lnm+"_shape", ly.Shape().Shapes()); err != nil {
+			return err
+		}
+		lays = append(lays, lnm)
+	}
+	var pnms []string
+	if nr.Wts {
+		var err error
+		if pnms, err = nz.AddPrjnWts(ss.Net); err != nil {
+			return err
+		}
+	}
+	if err := nz.AddStrings("layers", lays); err != nil {
+		return err
+	}
+	if err := nz.AddStrings("items", nr.Items); err != nil {
+		return err
+	}
+	if err := nz.AddStrings("tests", nr.Tests); err != nil {
+		return err
+	}
+	if err := nz.AddStrings("prjns", pnms); err != nil {
+		return err
+	}
+	if err := nz.AddInts("run", []int{trn.Run.Cur}); err != nil {
+		return err
+	}
+	return nz.AddInts("epoch", []int{trn.Epoch.Prv})
+}
+
+// SaveNpyRec saves the activity recorded by the NpyRec during the last TestAll to
+// the NpzFileName of the current run and epoch, in its Dir
+func (ss *Sim) SaveNpyRec() {
+	trn := ss.TrainEnv.Ctrs()
+	fnm := filepath.Join(ss.NpyRec.Dir, ss.NpzFileName(trn.Run.Cur, trn.Epoch.Prv))
+	if err := ss.SaveNpz(fnm); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/emer/etable/etensor"
+)
+
+func TestWriteNpy(t *testing.T) {
+	for _, tc := range []struct {
+		tsr   etensor.Tensor
+		descr string
+		shape string
+		nbyte int
+	}{
+		{etensor.NewFloat32([]int{2, 3}, nil, nil), "<f4", "(2, 3)", 2 * 3 * 4},
+		{etensor.NewFloat64([]int{5}, nil, nil), "<f8", "(5,)", 5 * 8},
+		{etensor.NewInt([]int{1, 2, 3}, nil, nil), "<i8", "(1, 2, 3)", 6 * 8},
+		{etensor.NewString([]int{2}, nil, nil), "<U1", "(2,)", 2 * 4},
+	} {
+		var buf bytes.Buffer
+		if err := WriteNpy(&buf, tc.tsr); err != nil {
+			t.Fatal(err)
+		}
+		b := buf.Bytes()
+		if string(b[:8]) != "\x93NUMPY\x01\x00" {
+			t.Errorf("%s: bad magic and version: %q", tc.descr, b[:8])
+			continue
+		}
+		hlen := int(binary.LittleEndian.Uint16(b[8:10]))
+		if (10+hlen)%64 != 0 {
+			t.Errorf("%s: data offset %d is not a multiple of 64", tc.descr, 10+hlen)
+		}
+		hdr := string(b[10 : 10+hlen])
+		if !strings.HasSuffix(hdr, "\n") {
+			t.Errorf("%s: header does not end with a newline: %q", tc.descr, hdr)
+		}
+		want := "{'descr': '" + tc.descr + "', 'fortran_order': False, 'shape': " + tc.shape + ", }"
+		if strings.TrimRight(hdr, " \n") != want {
+			t.Errorf("header %q, want %q", hdr, want)
+		}
+		if nd := len(b) - 10 - hlen; nd != tc.nbyte {
+			t.Errorf("%s: %d bytes of data, want %d", tc.descr, nd, tc.nbyte)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/emer/etable/etable"
+)
+
+// LogObserver is notified of the rows logged to the logs of a Sim that it observes,
+// e.g., to update their plots in the GUI, or, with no GUI, to write them to files
+// or report the progress of the runs.  Subscribe it with Observe.
+type LogObserver interface {
+	// LogRow is called after given row of given log is logged.  A row < 0 means
+	// that the log was updated as a whole, e.g., restored from a checkpoint, with
+	// no new row to record.
+	LogRow(dt *etable.Table, row int)
+}
+
+// LogObserverFunc is a function that is a LogObserver
+type LogObserverFunc func(dt *etable.Table, row int)
+
+// LogRow calls the function
+func (fn LogObserverFunc) LogRow(dt *etable.Table, row int) {
+	fn(dt, row)
+}
+
+// LogSub is a subscription of a LogObserver to the logs of a Sim
+type LogSub struct {
+	Obs  LogObserver     `desc:"the observer"`
+	Logs []*etable.Table `desc:"the logs it observes -- all of them if empty"`
+}
+
+// Observes returns true if the subscription is to given log
+func (ls *LogSub) Observes(dt *etable.Table) bool {
+	if len(ls.Logs) == 0 {
+		return true
+	}
+	for _, lg := range ls.Logs {
+		if lg == dt {
+			return true
+		}
+	}
+	return false
+}
+
+// Observe subscribes given observer to the rows logged to given logs of the Sim, or
+// to all of them if none, in the order of subscription
+func (ss *Sim) Observe(obs LogObserver, logs ...*etable.Table) {
+	ss.LogSubs = append(ss.LogSubs, LogSub{Obs: obs, Logs: logs})
+}
+
+// Unobserve removes all the subscriptions of given observer
+func (ss *Sim) Unobserve(obs LogObserver) {
+	subs := ss.LogSubs[:0]
+	for _, ls := range ss.LogSubs {
+		if ls.Obs != obs {
+			subs = append(subs, ls)
+		}
+	}
+	ss.LogSubs = subs
+}
+
+// Observed returns true if any observer is subscribed to given log
+func (ss *Sim) Observed(dt *etable.Table) bool {
+	for i := range ss.LogSubs {
+		if ss.LogSubs[i].Observes(dt) {
+			return true
+		}
+	}
+	return false
+}
+
+// LogUpdated notifies the observers of given log of given row logged to it, or
+// of the update of the whole log if row < 0
+func (ss *Sim) LogUpdated(dt *etable.Table, row int) {
+	for i := range ss.LogSubs {
+		ls := &ss.LogSubs[i]
+		if ls.Observes(dt) {
+			ls.Obs.LogRow(dt, row)
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////
+// 		LogFile
+
+// LogFile is a LogObserver that writes the rows of a log to a tab-separated file
+// as they are logged, with the headers of the log before the first one
+type LogFile struct {
+	Log  *etable.Table `desc:"the log written to the file"`
+	Name string        `desc:"name of the file"`
+	File *os.File      `desc:"the file"`
+	Hdrs bool          `desc:"true if the headers were written"`
+}
+
+// LogRow writes given row of the log to the file
+func (lf *LogFile) LogRow(dt *etable.Table, row int) {
+	if dt != lf.Log || row < 0 || lf.File == nil {
+		return
+	}
+	if !lf.Hdrs {
+		dt.WriteCSVHeaders(lf.File, etable.Tab)
+		lf.Hdrs = true
+	}
+	dt.WriteCSVRow(lf.File, row, etable.Tab)
+}
+
+// Close closes the file
+func (lf *LogFile) Close() error {
+	if lf.File == nil {
+		return nil
+	}
+	err := lf.File.Close()
+	lf.File = nil
+	return err
+}
+
+// OpenLogFile creates the file of given name to write the rows of given log to as
+// they are logged, and subscribes it to the log.  If the Sim was Resumed from a
+// checkpoint, the file continues from it, see ResumeLogFile.  The file is recorded
+// in the LogFiles of the Manifest.
+func (ss *Sim) OpenLogFile(fnm string, dt *etable.Table) (*LogFile, error) {
+	lf := &LogFile{Log: dt, Name: fnm}
+	var err error
+	if ss.Resumed {
+		lf.File, lf.Hdrs, err = ss.ResumeLogFile(fnm, dt)
+	} else {
+		lf.File, err = os.Create(fnm)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ss.LogFiles = append(ss.LogFiles, fnm)
+	ss.Observe(lf, dt)
+	return lf, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////
+// 		Progress
+
+// Progress is a LogObserver that reports the progress of the runs of a Sim with no
+// GUI: a line for each test epoch, with the Mem stats of the tests, and for each
+// finished run
+type Progress struct {
+	Sim *Sim      `desc:"the Sim reported on"`
+	W   io.Writer `desc:"writer to report to, e.g., os.Stdout"`
+}
+
+// ObserveProgress subscribes a Progress report to given writer to the TstEpcLog and
+// RunLog of the Sim, and returns it
+func (ss *Sim) ObserveProgress(w io.Writer) *Progress {
+	pr := &Progress{Sim: ss, W: w}
+	ss.Observe(pr, ss.TstEpcLog, ss.RunLog)
+	return pr
+}
+
+// LogRow reports given row of the TstEpcLog or RunLog
+func (pr *Progress) LogRow(dt *etable.Table, row int) {
+	if row < 0 {
+		return
+	}
+	ss := pr.Sim
+	run := int(dt.CellFloat("Run", row))
+	switch dt {
+	case ss.TstEpcLog:
+		var mems []string
+		for _, cl := range dt.ColNames {
+			if strings.HasSuffix(cl, " Mem") {
+				mems = append(mems, fmt.Sprintf("%s %.3g", cl, dt.CellFloat(cl, row)))
+			}
+		}
+		fmt.Fprintf(pr.W, "run %d epoch %d: %s\n", run, int(dt.CellFloat("Epoch", row)), strings.Join(mems, ", "))
+	case ss.RunLog:
+		fzero := dt.CellFloat("FirstZero", row)
+		if math.IsNaN(fzero) { // Mem not scored
+			fmt.Fprintf(pr.W, "run %d done: %d epochs\n", run, int(dt.CellFloat("NEpochs", row)))
+			break
+		}
+		fmt.Fprintf(pr.W, "run %d done: %d epochs, first zero mem errors at epoch %d\n", run, int(dt.CellFloat("NEpochs", row)), int(fzero))
+	}
+}
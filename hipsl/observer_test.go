@@ -0,0 +1,87 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/emer/etable/etable"
+)
+
+// chdirRoot changes to the root of the repository, with the pattern files, for
+// the rest of the test
+func chdirRoot(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(".."); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// TestHeadless runs the Sim with no GUI, through its log observers only
+func TestHeadless(t *testing.T) {
+	chdirRoot(t)
+	cfg := DefaultConfig()
+	if !cfg.NoGui {
+		t.Fatal("DefaultConfig is not NoGui")
+	}
+	ss, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fnm := filepath.Join(t.TempDir(), "epc.tsv")
+	lf, err := ss.OpenLogFile(fnm, ss.TstEpcLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prog bytes.Buffer
+	ss.ObserveProgress(&prog)
+	var trls int
+	ss.Observe(LogObserverFunc(func(dt *etable.Table, row int) {
+		if row >= 0 {
+			trls++
+		}
+	}), ss.TstTrlLog)
+
+	ss.TrainEpoch()
+	ss.TestAll()
+	if err := lf.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if ss.TstEpcLog.Rows == 0 {
+		t.Fatal("no rows in the TstEpcLog")
+	}
+	b, err := os.ReadFile(fnm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != ss.TstEpcLog.Rows+1 {
+		t.Errorf("log file has %d lines, want headers + %d rows", len(lines), ss.TstEpcLog.Rows)
+	}
+	if !strings.Contains(lines[0], "Epoch") {
+		t.Errorf("log file headers: %s", lines[0])
+	}
+	if trls < ss.TstTrlLog.Rows || trls == 0 {
+		t.Errorf("observed %d test trial rows, TstTrlLog has %d", trls, ss.TstTrlLog.Rows)
+	}
+	if np := strings.Count(prog.String(), "run 0 epoch "); np != ss.TstEpcLog.Rows {
+		t.Errorf("progress reported %d test epochs, want %d:\n%s", np, ss.TstEpcLog.Rows, prog.String())
+	}
+	if ss.Hooks.ViewUpdated != nil || ss.Hooks.Reconfigured != nil || ss.Hooks.Stopped != nil {
+		t.Error("headless Sim has GUI hooks set")
+	}
+	if len(ss.LogFiles) != 1 || ss.LogFiles[0] != fnm {
+		t.Errorf("LogFiles: %v", ss.LogFiles)
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"fmt"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// PairEnv generates the pair-structured statistical learning stream of
+// Schapiro et al. (2017) on the fly, instead of drawing from a pre-sampled table.
+// Each trial presents the previous item of the stream at PrevAct and the current
+// item at CurAct, on both Input and ECout.  The first item of a pair is followed
+// by its partner with probability WithinP, and the second item of a pair is
+// followed by the first item of another pair with probability BetweenP --
+// otherwise any item outside the current pair is chosen at random.
+type PairEnv struct {
+	SeqCtrs
+	Items       []string        `desc:"names of the items -- item i is represented by unit i in the patterns"`
+	Pairs       [][2]int        `desc:"pair assignment: indexes into Items of the first and second item of each pair"`
+	WithinP     float64         `desc:"probability that the first item of a pair is followed by its partner"`
+	BetweenP    float64         `desc:"probability that the second item of a pair is followed by the first item of another pair"`
+	Transitions bool            `desc:"if true, every step of the stream is a trial, including between-pair transitions -- otherwise only within-pair steps are presented, as in Train_pairs_without_transitions_go.dat"`
+	PrevAct     float32         `desc:"activity of the previous item in the overlap pattern"`
+	CurAct      float32         `desc:"activity of the current item in the overlap pattern"`
+	NUnits      int             `desc:"number of units in the patterns -- if 0, uses number of Items"`
+	Prev        int             `inactive:"+" desc:"index of the previous item in the stream"`
+	Cur         int             `inactive:"+" desc:"index of the current item in the stream"`
+	Pattern     etensor.Float32 `desc:"current pattern, applied to Input and ECout -- TrialName is the previous then current item name (e.g., AB)"`
+	PairOf      []int           `view:"-" desc:"index of the pair each item belongs to, -1 if none"`
+}
+
+// Defaults sets the 8 items A-H in pairs AB, CD, EF, GH, with deterministic
+// within-pair transitions and uniform transitions between pairs.
+func (ev *PairEnv) Defaults() {
+	ev.Items = []string{"A", "B", "C", "D", "E", "F", "G", "H"}
+	ev.Pairs = [][2]int{{0, 1}, {2, 3}, {4, 5}, {6, 7}}
+	ev.WithinP = 1
+	ev.BetweenP = 1
+	ev.Transitions = true
+	ev.PrevAct = 0.8
+	ev.CurAct = 1
+}
+
+func (ev *PairEnv) Validate() error {
+	ni := len(ev.Items)
+	if ni == 0 {
+		return fmt.Errorf("PairEnv: %v has no Items", ev.Nm)
+	}
+	if len(ev.Pairs) < 2 {
+		return fmt.Errorf("PairEnv: %v needs at least 2 Pairs, has %d", ev.Nm, len(ev.Pairs))
+	}
+	if ev.NUnits > 0 && ev.NUnits < ni {
+		return fmt.Errorf("PairEnv: %v NUnits %d < number of Items %d", ev.Nm, ev.NUnits, ni)
+	}
+	if !ev.Transitions && ev.WithinP <= 0 { // StepStream would never find a within-pair step
+		return fmt.Errorf("PairEnv: %v WithinP must be > 0 without Transitions, is %g", ev.Nm, ev.WithinP)
+	}
+	ev.PairOf = make([]int, ni)
+	for i := range ev.PairOf {
+		ev.PairOf[i] = -1
+	}
+	for pi, pr := range ev.Pairs {
+		for _, it := range pr {
+			if it < 0 || it >= ni {
+				return fmt.Errorf("PairEnv: %v pair %d item index %d out of range", ev.Nm, pi, it)
+			}
+			if ev.PairOf[it] >= 0 {
+				return fmt.Errorf("PairEnv: %v item %s is in more than one pair", ev.Nm, ev.Items[it])
+			}
+			ev.PairOf[it] = pi
+		}
+	}
+	return nil
+}
+
+// PatSize returns the number of units in the patterns
+func (ev *PairEnv) PatSize() int {
+	if ev.NUnits > 0 {
+		return ev.NUnits
+	}
+	return len(ev.Items)
+}
+
+func (ev *PairEnv) Init(run int) {
+	ev.Pattern.SetShape([]int{1, 1, ev.PatSize(), 1}, nil, nil)
+	ev.InitCtrs(run)
+	ev.Prev = -1
+	ev.Cur = ev.Rand.Intn(len(ev.Items)) // stream starts before the first trial
+}
+
+// NextItem returns the item that follows given item in the stream
+func (ev *PairEnv) NextItem(it int) int {
+	pi := ev.PairOf[it]
+	if pi < 0 {
+		return ev.OtherItem(it, -1)
+	}
+	pr := ev.Pairs[pi]
+	if it == pr[0] {
+		if ev.Rand.Float64() < ev.WithinP {
+			return pr[1]
+		}
+		return ev.OtherItem(it, pi)
+	}
+	if ev.Rand.Float64() < ev.BetweenP {
+		oi := ev.Rand.Intn(len(ev.Pairs) - 1)
+		if oi >= pi {
+			oi++
+		}
+		return ev.Pairs[oi][0]
+	}
+	return ev.OtherItem(it, pi)
+}
+
+// OtherItem returns a random item other than given item and outside of given pair
+func (ev *PairEnv) OtherItem(it, pi int) int {
+	var cands []int
+	for i := range ev.Items {
+		if i == it || (pi >= 0 && ev.PairOf[i] == pi) {
+			continue
+		}
+		cands = append(cands, i)
+	}
+	return cands[ev.Rand.Intn(len(cands))]
+}
+
+// IsWithin returns true if the step from prev to cur item is within a pair
+func (ev *PairEnv) IsWithin(prev, cur int) bool {
+	pi := ev.PairOf[cur]
+	return pi >= 0 && ev.Pairs[pi][0] == prev && ev.Pairs[pi][1] == cur
+}
+
+// ItemNames returns the names of the items
+func (ev *PairEnv) ItemNames() []string {
+	return ev.Items
+}
+
+// PairName returns the name of the pair (e.g., AB) that the item of given name
+// belongs to, or empty if it is not an item in a pair
+func (ev *PairEnv) PairName(item string) string {
+	it := ItemIdx(ev.Items, item)
+	if it < 0 || ev.PairOf[it] < 0 {
+		return ""
+	}
+	pr := ev.Pairs[ev.PairOf[it]]
+	return ev.Items[pr[0]] + ev.Items[pr[1]]
+}
+
+// Partner returns the index of the item of given name and of its partner
+// in its pair -- -1 if not found or not in a pair
+func (ev *PairEnv) Partner(item string) (it, partner int) {
+	it = ItemIdx(ev.Items, item)
+	if it < 0 || ev.PairOf[it] < 0 {
+		return it, -1
+	}
+	pr := ev.Pairs[ev.PairOf[it]]
+	if pr[0] == it {
+		return it, pr[1]
+	}
+	return it, pr[0]
+}
+
+// ConfigRecogTable configures given table with the items of the recognition test:
+// each pair as a target, and as lures, the across-pair pairings of the second item
+// of each pair with the first item of the next pair (e.g., BC), which follow each
+// other in the stream only across the boundary of pairs, and the novel combinations
+// of the first item of each pair with the second item of the next pair (e.g., AD),
+// which never do if WithinP is 1
+func (ev *PairEnv) ConfigRecogTable(dt *etable.Table) {
+	np := len(ev.Pairs)
+	rp := &RecogPairs{}
+	for pi, pr := range ev.Pairs {
+		nx := ev.Pairs[(pi+1)%np]
+		rp.Target = append(rp.Target, pr)
+		rp.Across = append(rp.Across, [2]int{pr[1], nx[0]})
+		rp.Novel = append(rp.Novel, [2]int{pr[0], nx[1]})
+	}
+	ConfigRecogTable(dt, ev.Items, ev.PatSize(), rp, ev.PrevAct, ev.CurAct)
+}
+
+// StepStream advances the stream to the next item to be presented
+func (ev *PairEnv) StepStream() {
+	for {
+		ev.Prev = ev.Cur
+		ev.Cur = ev.NextItem(ev.Cur)
+		if ev.Transitions || ev.IsWithin(ev.Prev, ev.Cur) {
+			break
+		}
+	}
+}
+
+// SetPattern sets the Pattern and TrialName from the Prev and Cur items
+func (ev *PairEnv) SetPattern() {
+	SetOverlapPattern(&ev.Pattern, ev.Prev, ev.Cur, ev.PrevAct, ev.CurAct)
+	ev.TrialName.Set(ev.Items[ev.Prev] + ev.Items[ev.Cur])
+}
+
+func (ev *PairEnv) Step() bool {
+	ev.StepCtrs()
+	ev.StepStream()
+	ev.SetPattern()
+	return true
+}
+
+func (ev *PairEnv) State(element string) etensor.Tensor {
+	switch element {
+	case "Input", "ECout":
+		return &ev.Pattern
+	}
+	return nil
+}
+
+// Compile-time check that implements SeqEnv interface
+var _ SeqEnv = (*PairEnv)(nil)
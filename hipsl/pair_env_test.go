@@ -0,0 +1,148 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"math"
+	"testing"
+)
+
+// newPairEnv returns a PairEnv of the default items and pairs with given
+// transition probabilities, initialized with given seed
+func newPairEnv(t *testing.T, withinP, betweenP float64, seed int64) *PairEnv {
+	ev := &PairEnv{}
+	ev.Defaults()
+	ev.Nm = "TestPairEnv"
+	ev.WithinP = withinP
+	ev.BetweenP = betweenP
+	ev.Trial.Max = 100
+	if err := ev.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	ev.SetSeed(seed)
+	ev.Init(0)
+	return ev
+}
+
+func TestPairEnvTransitions(t *testing.T) {
+	ev := newPairEnv(t, 0.7, 0.6, 1)
+	const n = 40000
+	var nfirst, within, nsecond, toFirst float64
+	for i := 0; i < n; i++ {
+		ev.Step()
+		pi := ev.PairOf[ev.Prev]
+		if ev.Prev == ev.Pairs[pi][0] {
+			nfirst++
+			if ev.Cur == ev.Pairs[pi][1] {
+				within++
+			}
+			continue
+		}
+		nsecond++
+		if cp := ev.PairOf[ev.Cur]; cp != pi && ev.Cur == ev.Pairs[cp][0] {
+			toFirst++
+		}
+	}
+	if p := within / nfirst; math.Abs(p-0.7) > 0.02 {
+		t.Errorf("within-pair transition frequency %g, want 0.7", p)
+	}
+	// BetweenP, else a random item outside the pair, half of which are first items
+	if p := toFirst / nsecond; math.Abs(p-(0.6+0.4*0.5)) > 0.02 {
+		t.Errorf("between-pair transition frequency %g, want 0.8", p)
+	}
+
+	ev = newPairEnv(t, 1, 1, 2)
+	for i := 0; i < 1000; i++ {
+		ev.Step()
+		pi := ev.PairOf[ev.Prev]
+		if ev.Prev == ev.Pairs[pi][0] && !ev.IsWithin(ev.Prev, ev.Cur) {
+			t.Fatalf("step %d: %s is not followed by its partner", i, ev.TrialName.Cur)
+		}
+		if ev.Prev == ev.Pairs[pi][1] && (ev.PairOf[ev.Cur] == pi || ev.Pairs[ev.PairOf[ev.Cur]][0] != ev.Cur) {
+			t.Fatalf("step %d: %s is not followed by the first item of another pair", i, ev.TrialName.Cur)
+		}
+	}
+
+	ev = newPairEnv(t, 0.5, 0.5, 3)
+	ev.Transitions = false
+	for i := 0; i < 1000; i++ {
+		ev.Step()
+		if !ev.IsWithin(ev.Prev, ev.Cur) {
+			t.Fatalf("step %d: %s is not within a pair without Transitions", i, ev.TrialName.Cur)
+		}
+	}
+}
+
+func TestPairEnvPattern(t *testing.T) {
+	ev := newPairEnv(t, 1, 1, 1)
+	for i := 0; i < 20; i++ {
+		ev.Step()
+		if nm := ev.Items[ev.Prev] + ev.Items[ev.Cur]; ev.TrialName.Cur != nm {
+			t.Errorf("TrialName %s, want %s", ev.TrialName.Cur, nm)
+		}
+		if ev.State("Input") != &ev.Pattern || ev.State("ECout") != &ev.Pattern {
+			t.Fatal("Input and ECout are not the Pattern")
+		}
+		if ev.Pattern.Len() != len(ev.Items) {
+			t.Fatalf("pattern of %d units, want %d", ev.Pattern.Len(), len(ev.Items))
+		}
+		for ui, v := range ev.Pattern.Values {
+			want := float32(0)
+			switch ui {
+			case ev.Prev:
+				want = 0.8
+			case ev.Cur:
+				want = 1
+			}
+			if v != want {
+				t.Errorf("%s: unit %d is %g, want %g", ev.TrialName.Cur, ui, v, want)
+			}
+		}
+	}
+}
+
+func TestPairEnvSeed(t *testing.T) {
+	stream := func(seed int64) []string {
+		ev := newPairEnv(t, 0.7, 0.6, seed)
+		var nms []string
+		for i := 0; i < 200; i++ {
+			ev.Step()
+			nms = append(nms, ev.TrialName.Cur)
+		}
+		return nms
+	}
+	s1, s2, s3 := stream(5), stream(5), stream(6)
+	same := true
+	for i := range s1 {
+		if s1[i] != s2[i] {
+			t.Fatalf("step %d: same seed gives %s and %s", i, s1[i], s2[i])
+		}
+		same = same && s1[i] == s3[i]
+	}
+	if same {
+		t.Error("different seeds give the same stream")
+	}
+}
+
+func TestPairEnvValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		set  func(ev *PairEnv)
+	}{
+		{"no items", func(ev *PairEnv) { ev.Items = nil }},
+		{"one pair", func(ev *PairEnv) { ev.Pairs = ev.Pairs[:1] }},
+		{"item out of range", func(ev *PairEnv) { ev.Pairs[0][1] = 8 }},
+		{"item in two pairs", func(ev *PairEnv) { ev.Pairs[1][0] = 0 }},
+		{"too few units", func(ev *PairEnv) { ev.NUnits = 4 }},
+		{"no within steps", func(ev *PairEnv) { ev.Transitions = false; ev.WithinP = 0 }},
+	} {
+		ev := &PairEnv{}
+		ev.Defaults()
+		tc.set(ev)
+		if err := ev.Validate(); err == nil {
+			t.Errorf("%s: Validate did not fail", tc.name)
+		}
+	}
+}
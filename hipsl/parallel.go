@@ -0,0 +1,276 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/emer/emergent/params"
+	"github.com/emer/etable/etable"
+	"github.com/emer/leabra/leabra"
+)
+
+// GlobalRandMu guards the seeding and use of the global math/rand generator,
+// from which the initial weights are drawn, so that the subjects of RunSubjects
+// running on their own goroutines each get the weights of their own seed.
+// The Act.Noise of the layers is also drawn from it, on every cycle, which cannot
+// be guarded, so RunSubjects rejects networks with noise, including the
+// Replay.ActNoise.
+var GlobalRandMu sync.Mutex
+
+// NoisyLayer returns the name of the first layer of given network with Act.Noise,
+// or "" if none has
+func NoisyLayer(net *leabra.Network) string {
+	for _, ly := range net.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		if ly.(leabra.LeabraLayer).AsLeabra().Act.Noise.Type != leabra.NoNoise {
+			return ly.Name()
+		}
+	}
+	return ""
+}
+
+// SubjectRun is the result of one run done by a subject of RunSubjects
+type SubjectRun struct {
+	Run       int           `desc:"run number"`
+	TstTrlLog *etable.Table `desc:"testing trial log rows of all the epochs of the run, if the TstTrlLog is observed"`
+	TrnEpcLog *etable.Table `desc:"training epoch log of the run"`
+	TstEpcLog *etable.Table `desc:"testing epoch log of the run"`
+	RSALog    *etable.Table `desc:"RSA log of the run"`
+	ReplayLog *etable.Table `desc:"replay log of the run"`
+	WtEpcLog  *etable.Table `desc:"weight log of the run"`
+	RunLog    *etable.Table `desc:"RunLog row of the run"`
+}
+
+// NewSubject returns a new Sim with its own network, environments and random
+// generators, configured the same as this one, to do runs in parallel with it.
+// It runs without GUI, logs to no files, and saves no checkpoints.
+func (ss *Sim) NewSubject() (*Sim, error) {
+	sb := &Sim{}
+	sb.New()
+	sb.NoGui = true
+	// params are copied, as applying them records their matches in the Sels
+	b, err := json.Marshal(ss.Params)
+	if err == nil {
+		sb.Params = params.Sets{}
+		err = json.Unmarshal(b, &sb.Params)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("NewSubject: params: %v", err)
+	}
+	for _, ep := range [][2]SeqEnv{{&ss.PairEnv, &sb.PairEnv}, {&ss.GraphEnv, &sb.GraphEnv}, {&ss.AssocEnv, &sb.AssocEnv}} {
+		b, err := MarshalSeqEnv(ep[0])
+		if err == nil {
+			err = json.Unmarshal(b, ep[1])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("NewSubject: %v: %v", ep[0].Ctrs().Nm, err)
+		}
+	}
+	lss, err := ParseLesions(ss.Lesions.String())
+	if err != nil {
+		return nil, err
+	}
+	sb.ParamSet = ss.ParamSet
+	sb.Tag = ss.Tag
+	sb.RndSeed = ss.RndSeed
+	sb.MaxRuns = ss.MaxRuns
+	sb.StartRun = ss.StartRun
+	sb.MaxEpcs = ss.MaxEpcs
+	sb.TrialperEpc = ss.TrialperEpc
+	sb.NZeroStop = ss.NZeroStop
+	sb.TestInterval = ss.TestInterval
+	sb.MemThr = ss.MemThr
+	sb.RecogOn = ss.RecogOn
+	sb.RecogThr = ss.RecogThr
+	sb.Paradigm = ss.Paradigm
+	sb.Topology = ss.Topology
+	sb.Theta = ss.Theta
+	sb.Lesions = lss
+	sb.ActRec = ss.ActRec // records to a file of its own per run
+	sb.ActRec.File = nil
+	sb.ActRec.Writer = nil
+	sb.ActRec.Vals = nil
+	sb.ActRec.Trial = nil
+	sb.ActRec.ArrowFile = nil
+	sb.NpyRec = ss.NpyRec // saves files of its own per run and epoch
+	sb.NpyRec.Layers = append([]string{}, ss.NpyRec.Layers...)
+	sb.NpyRec.Acts = nil
+	sb.NpyRec.Vals = nil
+	sb.Replay = ss.Replay // seeded by NewRun
+	sb.Replay.NoiseLays = append([]string{}, ss.Replay.NoiseLays...)
+	sb.Replay.Layers = append([]string{}, ss.Replay.Layers...)
+	sb.Replay.Rand = nil
+	sb.Replay.Src = nil
+	sb.Replay.Vals = nil
+	sb.WtSnap = ss.WtSnap // saves files of its own per run and epoch
+	sb.WtSnap.Prjns = append([]string{}, ss.WtSnap.Prjns...)
+	sb.WtSnap.Prev = nil
+	sb.SaveWts = ss.SaveWts
+	sb.ManifestOn = ss.ManifestOn
+	sb.LogFiles = ss.LogFiles
+	sb.ParamsFiles = ss.ParamsFiles
+	sb.Config()
+	if ss.Observed(ss.TstTrlLog) { // keeps the rows of all its test epochs to merge
+		sb.SubjTstTrls = sb.TstTrlLog.Clone()
+		sb.SubjTstTrls.SetNumRows(0)
+		sb.Observe(LogObserverFunc(func(dt *etable.Table, row int) {
+			if row < 0 {
+				return
+			}
+			trl := sb.SubjTstTrls.Rows
+			sb.SubjTstTrls.SetNumRows(trl + 1)
+			for _, cl := range dt.ColNames {
+				sb.SubjTstTrls.CopyCell(cl, trl, dt, cl, row)
+			}
+		}), sb.TstTrlLog)
+	}
+	return sb, nil
+}
+
+// RunSubjects does the runs from StartRun to MaxRuns-1 with n subjects in
+// parallel, each made by NewSubject and running on its own goroutine, taking the
+// next run to do as it finishes the previous one.  As every run has the seeds of
+// its run number, the results are the same as those of Train, as long as there
+// is no Act.Noise in the layers nor Replay.ActNoise, which is then an error (see
+// GlobalRandMu): they are merged in the order of the runs into the TrnEpcLog,
+// TstEpcLog, RSALog, ReplayLog, WtEpcLog and RunLog, and the TstTrlLog if it is
+// observed, and their files.
+func (ss *Sim) RunSubjects(n int) error {
+	if ss.CkptInterval > 0 || ss.Resumed {
+		return fmt.Errorf("RunSubjects: checkpoints are not supported with parallel subjects")
+	}
+	if lnm := NoisyLayer(ss.Net); lnm != "" { // params applied by Init
+		return fmt.Errorf("RunSubjects: layer %s has Act.Noise, drawn from the global random generator shared by the subjects, so their runs would not be reproducible -- run them sequentially", lnm)
+	}
+	if ss.Replay.On && ss.Replay.ActNoise > 0 {
+		return fmt.Errorf("RunSubjects: Replay.ActNoise is drawn from the global random generator shared by the subjects, so their runs would not be reproducible -- run them sequentially")
+	}
+	nruns := ss.MaxRuns - ss.StartRun
+	if n > nruns {
+		n = nruns
+	}
+	if n < 1 {
+		return nil
+	}
+	sbs := make([]*Sim, n)
+	for i := range sbs {
+		sb, err := ss.NewSubject()
+		if err != nil {
+			return err
+		}
+		sbs[i] = sb
+	}
+	fmt.Printf("Running %d runs with %d parallel subjects\n", nruns, n)
+
+	runs := make(chan int, nruns)
+	for run := ss.StartRun; run < ss.MaxRuns; run++ {
+		runs <- run
+	}
+	close(runs)
+	res := make(chan *SubjectRun, nruns)
+	var wg sync.WaitGroup
+	for _, sb := range sbs {
+		wg.Add(1)
+		go func(sb *Sim) {
+			defer wg.Done()
+			for run := range runs {
+				res <- sb.SubjectRun(run)
+			}
+		}(sb)
+	}
+	go func() {
+		wg.Wait()
+		close(res)
+	}()
+
+	done := make(map[int]*SubjectRun) // finished runs waiting for those before them
+	next := ss.StartRun
+	for sr := range res {
+		done[sr.Run] = sr
+		for ; done[next] != nil; next++ {
+			ss.MergeSubjectRun(done[next])
+			delete(done, next)
+		}
+	}
+	ss.Stopped()
+	return nil
+}
+
+// SubjectRun does given run, as a subject of RunSubjects, and returns its logs
+func (ss *Sim) SubjectRun(run int) *SubjectRun {
+	ss.StartRun = run
+	ss.MaxRuns = run + 1
+	ss.Init()
+	ss.Train()
+	sr := &SubjectRun{Run: run}
+	if ss.SubjTstTrls != nil {
+		sr.TstTrlLog = ss.SubjTstTrls.Clone()
+		ss.SubjTstTrls.SetNumRows(0)
+	}
+	sr.TrnEpcLog = ss.TrnEpcLog.Clone()
+	sr.TstEpcLog = ss.TstEpcLog.Clone()
+	sr.RSALog = ss.RSALog.Clone()
+	sr.ReplayLog = ss.ReplayLog.Clone()
+	sr.WtEpcLog = ss.WtEpcLog.Clone()
+	sr.RunLog = ss.RunLog.Clone()
+	ss.RunLog.SetNumRows(0)
+	return sr
+}
+
+// MergeSubjectRun adds the logs of given run of a subject of RunSubjects to
+// those of this Sim, notifying their observers of each row, e.g., to write them
+// to its log files
+func (ss *Sim) MergeSubjectRun(sr *SubjectRun) {
+	if sr.TstTrlLog != nil { // rows go back to their row in the epoch, as logged
+		for srow := 0; srow < sr.TstTrlLog.Rows; srow++ {
+			row := int(sr.TstTrlLog.CellFloat("Trial", srow))
+			if row >= ss.TstTrlLog.Rows {
+				ss.TstTrlLog.SetNumRows(row + 1)
+			}
+			for _, cl := range ss.TstTrlLog.ColNames {
+				ss.TstTrlLog.CopyCell(cl, row, sr.TstTrlLog, cl, srow)
+			}
+			ss.LogUpdated(ss.TstTrlLog, row)
+		}
+	}
+	ss.TrnEpcLog.SetNumRows(0)
+	ss.TstEpcLog.SetNumRows(0)
+	ss.RSALog.SetNumRows(0)
+	ss.ReplayLog.SetNumRows(0)
+	ss.WtEpcLog.SetNumRows(0)
+	for _, lg := range []struct {
+		dt, sdt *etable.Table
+	}{{ss.TrnEpcLog, sr.TrnEpcLog}, {ss.TstEpcLog, sr.TstEpcLog}, {ss.RSALog, sr.RSALog}, {ss.ReplayLog, sr.ReplayLog}, {ss.WtEpcLog, sr.WtEpcLog}, {ss.RunLog, sr.RunLog}} {
+		for srow := 0; srow < lg.sdt.Rows; srow++ {
+			row := lg.dt.Rows
+			lg.dt.SetNumRows(row + 1)
+			for _, cl := range lg.dt.ColNames {
+				lg.dt.CopyCell(cl, row, lg.sdt, cl, srow)
+			}
+			if lg.dt == ss.RunLog {
+				ss.LogRunStats(ss.RunLog)
+			}
+			ss.LogUpdated(lg.dt, row)
+		}
+	}
+}
+
+// RunAll does all the runs from the current state: with RunSubjects if Subjects
+// is > 1, otherwise with Train
+func (ss *Sim) RunAll() {
+	if ss.Subjects > 1 {
+		if err := ss.RunSubjects(ss.Subjects); err != nil {
+			log.Println(err)
+		}
+		return
+	}
+	ss.Train()
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/emer/emergent/params"
+	"github.com/emer/etable/etable"
+)
+
+// collectRows returns a table that gets a copy of every row logged to given log
+// of the Sim, over all its runs
+func collectRows(ss *Sim, dt *etable.Table) *etable.Table {
+	all := dt.Clone()
+	all.SetNumRows(0)
+	ss.Observe(LogObserverFunc(func(dt *etable.Table, row int) {
+		if row < 0 {
+			return
+		}
+		arow := all.Rows
+		all.SetNumRows(arow + 1)
+		for _, cl := range dt.ColNames {
+			all.CopyCell(cl, arow, dt, cl, row)
+		}
+	}), dt)
+	return all
+}
+
+// TestRunSubjects checks that runs done by parallel subjects log the same as
+// when done in sequence by Train
+func TestRunSubjects(t *testing.T) {
+	chdirRoot(t)
+	pfile := filepath.Join(t.TempDir(), "epcs.json") // MaxEpcs is set by the Base params
+	epcs := params.Sets{{Name: "Base", Sheets: params.Sheets{"Sim": &params.Sheet{
+		{Sel: "Sim", Params: params.Params{"Sim.MaxEpcs": "2"}},
+	}}}}
+	if err := SaveParamsSets(epcs, pfile); err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	cfg.ParamsFiles = []string{pfile}
+	cfg.MaxRuns = 2
+	cfg.TrialperEpc = 40
+
+	seq, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seqTst := collectRows(seq, seq.TstEpcLog)
+	seq.Train()
+
+	par, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parTst := collectRows(par, par.TstEpcLog)
+	if err := par.RunSubjects(2); err != nil {
+		t.Fatal(err)
+	}
+
+	if seq.RunLog.Rows != 2 || seqTst.Rows < 4 {
+		t.Fatalf("sequential runs logged %d runs and %d test epochs", seq.RunLog.Rows, seqTst.Rows)
+	}
+	sameLogs(t, "RunLog", seq.RunLog, par.RunLog)
+	sameLogs(t, "TstEpcLog", seqTst, parTst)
+}
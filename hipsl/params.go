@@ -1,6 +1,6 @@
 // File generated by params.SaveGoCode
 
-package main
+package hipsl
 
 import "github.com/emer/emergent/params"
 
@@ -172,6 +172,17 @@ var SavedParamsSets = params.Sets{
 					"Sim.MaxEpcs": "10",
 					//"Sim.MaxRuns": "1",
 				}},
+			{Sel: "Sim", Desc: "Go quarter timing: ActQ1 (ActMid) at 25, ActM at 75, ActP at 100",
+				Params: params.Params{
+					"Sim.Theta.Train.Q1.Cycles": "25",
+					"Sim.Theta.Train.Q2.Cycles": "25",
+					"Sim.Theta.Train.Q3.Cycles": "25",
+					"Sim.Theta.Train.Q4.Cycles": "25",
+					"Sim.Theta.Test.Q1.Cycles":  "25",
+					"Sim.Theta.Test.Q2.Cycles":  "25",
+					"Sim.Theta.Test.Q3.Cycles":  "25",
+					"Sim.Theta.Test.Q4.Cycles":  "25",
+				}},
 		},
 	}},
 	{Name: "NoCHL", Desc: "no learning in CHL main hip pathways -- for debugging auto-encoder", Sheets: params.Sheets{
@@ -224,4 +235,20 @@ var SavedParamsSets = params.Sets{
 		},
 		"Sim": &params.Sheet{},
 	}},
+	{Name: "CppTiming", Desc: "timing of the C++ model: ActMid at 40, ActM at 80, ActP at 100 -- instead of the Go quarters of 25 cycles", Sheets: params.Sheets{
+		"Network": &params.Sheet{},
+		"Sim": &params.Sheet{
+			{Sel: "Sim", Desc: "CA3 recall from 40 to 80, plus phase from 80",
+				Params: params.Params{
+					"Sim.Theta.Train.Q1.Cycles": "40",
+					"Sim.Theta.Train.Q2.Cycles": "20",
+					"Sim.Theta.Train.Q3.Cycles": "20",
+					"Sim.Theta.Train.Q4.Cycles": "20",
+					"Sim.Theta.Test.Q1.Cycles":  "40",
+					"Sim.Theta.Test.Q2.Cycles":  "20",
+					"Sim.Theta.Test.Q3.Cycles":  "20",
+					"Sim.Theta.Test.Q4.Cycles":  "20",
+				}},
+		},
+	}},
 }
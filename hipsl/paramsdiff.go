@@ -0,0 +1,293 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/emer/emergent/params"
+	"github.com/emer/leabra/leabra"
+	"github.com/goki/gi/gi"
+)
+
+// ParamsDiff is one difference between two sources of params: of a param of a
+// selector of their sets, or of the effective value of a param of a layer or
+// projection after they are applied
+type ParamsDiff struct {
+	Kind  string `desc:"Sel for a param of a selector, Layer or Prjn for an effective value"`
+	Name  string `desc:"sheet and selector (e.g., Network #CA3ToCA3), or name of the layer or projection"`
+	Param string `desc:"path of the param, e.g., Prjn.Learn.Lrate for a Sel, Learn.Lrate for a Prjn"`
+	A     string `desc:"value in the first source -- empty if not set"`
+	B     string `desc:"value in the second source -- empty if not set"`
+}
+
+// ParamsSourceSets returns the sets of given source of params, in the order they
+// are applied: compiled for the compiled-in SavedParamsSets, a JSON or TOML file
+// of param sets (e.g., the hip.params of the leabra hip example), or defaults for
+// no params, i.e., the defaults of the network after net.Defaults().  A :Set
+// suffix applies that set on top of Base, e.g., compiled:CppTiming -- a source
+// that is a file of its own, e.g., C:\hip.params, is not split.
+func ParamsSourceSets(src string) (params.Sets, error) {
+	if src == "defaults" {
+		return nil, nil
+	}
+	snm := ""
+	all, err := paramsSourceAll(src)
+	if ci := strings.LastIndex(src, ":"); err != nil && ci > 0 {
+		if sall, serr := paramsSourceAll(src[:ci]); serr == nil { // a :Set suffix
+			if _, serr = sall.SetByNameTry(src[ci+1:]); serr != nil {
+				return nil, serr
+			}
+			all, snm, err = sall, src[ci+1:], nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sets params.Sets
+	if ps, err := all.SetByNameTry("Base"); err == nil {
+		sets = append(sets, ps)
+	}
+	if snm != "" && snm != "Base" {
+		ps, err := all.SetByNameTry(snm)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, ps)
+	}
+	return sets, nil
+}
+
+// paramsSourceAll returns all the sets of given source of params, without a :Set
+// suffix: the compiled-in SavedParamsSets, or those of a file
+func paramsSourceAll(src string) (params.Sets, error) {
+	if src == "compiled" {
+		return SavedParamsSets, nil
+	}
+	return OpenParamsSets(src)
+}
+
+// SelParams returns the value of each param of each selector of given sets, by
+// sheet and selector, e.g., Network #CA3ToCA3 -- later sets and selectors
+// override earlier ones, as when they are applied
+func SelParams(sets params.Sets) map[string]map[string]string {
+	sps := make(map[string]map[string]string)
+	for _, ps := range sets {
+		for snm, sh := range ps.Sheets {
+			for _, sl := range *sh {
+				nm := snm + " " + sl.Sel
+				if sps[nm] == nil {
+					sps[nm] = make(map[string]string)
+				}
+				for pt, v := range sl.Params {
+					sps[nm][pt] = v
+				}
+			}
+		}
+	}
+	return sps
+}
+
+// NetParams returns the values of the params of the layers and projections of the
+// network of the Topology after net.Defaults() and the Network sheets of given
+// sets are applied, by Layer or Prjn and name, in the order of the network
+func (ss *Sim) NetParams(sets params.Sets) (nms []string, nps map[string]map[string]string) {
+	net := &leabra.Network{}
+	net.InitName(net, "Hip")
+	ss.Topology.Config(net, ss.ECSize())
+	net.Defaults()
+	for _, ps := range sets {
+		if sh, ok := ps.Sheets["Network"]; ok {
+			net.ApplyParams(sh, false)
+		}
+	}
+	mf := &Manifest{}
+	mf.RecordParams(net, nil)
+	nps = make(map[string]map[string]string)
+	add := func(nm string, grps map[string]interface{}) {
+		nms = append(nms, nm)
+		nps[nm] = make(map[string]string)
+		for gnm, grp := range grps {
+			b, _ := json.Marshal(grp)
+			FlattenJSON(b, gnm, nps[nm])
+		}
+	}
+	for _, ml := range mf.Layers {
+		add("Layer "+ml.Name, map[string]interface{}{"Act": ml.Act, "Inhib": ml.Inhib, "Learn": ml.Learn})
+		for _, mp := range ml.Prjns {
+			grps := map[string]interface{}{"WtInit": mp.WtInit, "WtScale": mp.WtScale, "Learn": mp.Learn}
+			if mp.CHL != nil {
+				grps["CHL"] = mp.CHL
+			}
+			add("Prjn "+mp.Name, grps)
+		}
+	}
+	return
+}
+
+// FlattenJSON adds the leaf values of given JSON object to vals, by their path
+// from given prefix, e.g., Act.Gbar.L
+func FlattenJSON(b []byte, pfx string, vals map[string]string) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber() // values exactly as marshaled
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return
+	}
+	var flat func(pth string, v interface{})
+	flat = func(pth string, v interface{}) {
+		if obj, ok := v.(map[string]interface{}); ok {
+			for k, cv := range obj {
+				flat(pth+"."+k, cv)
+			}
+			return
+		}
+		vals[pth] = fmt.Sprint(v)
+	}
+	flat(pfx, v)
+}
+
+// DiffParamMaps appends the differences between the params of given maps, by
+// name in given order, then param path, as the given kind of ParamsDiff
+func DiffParamMaps(diffs []ParamsDiff, kind string, nms []string, a, b map[string]map[string]string) []ParamsDiff {
+	for _, nm := range nms {
+		pts := make(map[string]bool)
+		for pt := range a[nm] {
+			pts[pt] = true
+		}
+		for pt := range b[nm] {
+			pts[pt] = true
+		}
+		spts := make([]string, 0, len(pts))
+		for pt := range pts {
+			spts = append(spts, pt)
+		}
+		sort.Strings(spts)
+		for _, pt := range spts {
+			av, bv := a[nm][pt], b[nm][pt]
+			if !SameParamVal(av, bv) {
+				diffs = append(diffs, ParamsDiff{Kind: kind, Name: strings.TrimPrefix(nm, kind+" "), Param: pt, A: av, B: bv})
+			}
+		}
+	}
+	return diffs
+}
+
+// SameParamVal returns true if given param values are the same, as strings or
+// as numbers, e.g., 1 and 1.0
+func SameParamVal(a, b string) bool {
+	if a == b {
+		return true
+	}
+	af, aerr := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	bf, berr := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	return aerr == nil && berr == nil && af == bf
+}
+
+// ParamsDiffs returns the differences between two sources of params (see
+// ParamsSourceSets): first of the params of their selectors, then of the
+// effective values of the params of each layer and projection after they are applied
+func (ss *Sim) ParamsDiffs(srcA, srcB string) ([]ParamsDiff, error) {
+	setsA, err := ParamsSourceSets(srcA)
+	if err != nil {
+		return nil, err
+	}
+	setsB, err := ParamsSourceSets(srcB)
+	if err != nil {
+		return nil, err
+	}
+	var diffs []ParamsDiff
+	selA, selB := SelParams(setsA), SelParams(setsB)
+	sels := make(map[string]bool)
+	for nm := range selA {
+		sels[nm] = true
+	}
+	for nm := range selB {
+		sels[nm] = true
+	}
+	snms := make([]string, 0, len(sels))
+	for nm := range sels {
+		snms = append(snms, nm)
+	}
+	sort.Strings(snms)
+	diffs = DiffParamMaps(diffs, "Sel", snms, selA, selB)
+
+	nms, netA := ss.NetParams(setsA)
+	_, netB := ss.NetParams(setsB)
+	var lnms, pnms []string
+	for _, nm := range nms {
+		if strings.HasPrefix(nm, "Layer ") {
+			lnms = append(lnms, nm)
+		} else {
+			pnms = append(pnms, nm)
+		}
+	}
+	diffs = DiffParamMaps(diffs, "Layer", lnms, netA, netB)
+	diffs = DiffParamMaps(diffs, "Prjn", pnms, netA, netB)
+	return diffs, nil
+}
+
+// WriteParamsDiffs writes given diffs between sources srcA and srcB as CSV if
+// csvFmt, otherwise as a markdown table
+func WriteParamsDiffs(w io.Writer, diffs []ParamsDiff, srcA, srcB string, csvFmt bool) error {
+	hdrs := []string{"Kind", "Name", "Param", srcA, srcB}
+	if csvFmt {
+		cw := csv.NewWriter(w)
+		cw.Write(hdrs)
+		for _, df := range diffs {
+			cw.Write([]string{df.Kind, df.Name, df.Param, df.A, df.B})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	fmt.Fprintf(w, "| %s |\n", strings.Join(hdrs, " | "))
+	fmt.Fprintf(w, "|%s\n", strings.Repeat(" --- |", len(hdrs)))
+	for _, df := range diffs {
+		fmt.Fprintf(w, "| %s | %s | %s | %s | %s |\n", df.Kind, df.Name, df.Param, df.A, df.B)
+	}
+	return nil
+}
+
+// DiffParams writes the differences between two sources of params to given file:
+// CSV if it has a .csv extension, otherwise markdown -- to stdout if no file.  A
+// source is compiled, a JSON or TOML file of param sets (e.g., the hip.params of
+// the leabra hip example), or defaults, optionally followed by :Set to apply that
+// set on top of Base.  The differences are those of the params of the selectors,
+// then of the effective values of the params of each layer and projection.
+func (ss *Sim) DiffParams(srcA, srcB string, filename gi.FileName) error {
+	diffs, err := ss.ParamsDiffs(srcA, srcB)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	if filename == "" {
+		err = WriteParamsDiffs(os.Stdout, diffs, srcA, srcB, false)
+	} else {
+		var f *os.File
+		f, err = os.Create(string(filename))
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		err = WriteParamsDiffs(f, diffs, srcA, srcB, filepath.Ext(string(filename)) == ".csv")
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/emer/emergent/params"
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/toml"
+)
+
+// ParamsFile is the layout of a TOML params file, which cannot hold a list at
+// the top level: the sets are an array of tables, [[Sets]]
+type ParamsFile struct {
+	Sets params.Sets `desc:"the param sets"`
+}
+
+// OpenParamsSets reads param sets from given file: TOML if it has a .toml
+// extension, as an array of [[Sets]] tables, otherwise JSON, as saved by SaveJSON
+func OpenParamsSets(filename string) (params.Sets, error) {
+	if filepath.Ext(filename) == ".toml" {
+		pf := &ParamsFile{}
+		if err := toml.Open(pf, filename); err != nil {
+			return nil, err
+		}
+		return pf.Sets, nil
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	sets := params.Sets{}
+	if err := json.Unmarshal(b, &sets); err != nil {
+		return nil, fmt.Errorf("OpenParamsSets: %v: %v", filename, err)
+	}
+	return sets, nil
+}
+
+// SaveParamsSets writes given param sets to given file: Go code initializing
+// SavedParamsSets as in params.go if it has a .go extension, TOML if .toml,
+// otherwise JSON
+func SaveParamsSets(sets params.Sets, filename string) error {
+	switch filepath.Ext(filename) {
+	case ".go":
+		return sets.SaveGoCode(gi.FileName(filename))
+	case ".toml":
+		return toml.Save(&ParamsFile{Sets: sets}, filename)
+	}
+	return sets.SaveJSON(gi.FileName(filename))
+}
+
+// MergeParamsSets returns the base sets with given sets merged on top of them:
+// the sheets of a set with the name of a base set are appended to its sheets, so
+// that they override its params, and the other sets are added.  The base sets
+// are not modified.
+func MergeParamsSets(base, sets params.Sets) params.Sets {
+	mrg := append(params.Sets{}, base...)
+	for _, ps := range sets {
+		bi := -1
+		for i, bs := range mrg {
+			if bs.Name == ps.Name {
+				bi = i
+				break
+			}
+		}
+		if bi < 0 {
+			mrg = append(mrg, ps)
+			continue
+		}
+		bs := mrg[bi]
+		ms := &params.Set{Name: bs.Name, Desc: bs.Desc, Sheets: params.Sheets{}}
+		for snm, sh := range bs.Sheets {
+			msh := append(params.Sheet{}, *sh...)
+			ms.Sheets[snm] = &msh
+		}
+		for snm, sh := range ps.Sheets {
+			msh, ok := ms.Sheets[snm]
+			if !ok {
+				msh = &params.Sheet{}
+				ms.Sheets[snm] = msh
+			}
+			*msh = append(*msh, *sh...)
+		}
+		if ps.Desc != "" {
+			ms.Desc += " -- " + ps.Desc
+		}
+		mrg[bi] = ms
+	}
+	return mrg
+}
+
+// ReplaceParamsSet returns given sets with the one of the name of given set
+// replaced by it, or it added if there is none
+func ReplaceParamsSet(sets params.Sets, ps *params.Set) params.Sets {
+	rep := append(params.Sets{}, sets...)
+	for i, s := range rep {
+		if s.Name == ps.Name {
+			rep[i] = ps
+			return rep
+		}
+	}
+	return append(rep, ps)
+}
+
+// OpenParams loads param sets from a JSON or TOML file and merges them into the
+// Params: a Base set overrides the params of Base, a set named as an existing one
+// overrides its params, and the others are added, to be selected by ParamSet
+func (ss *Sim) OpenParams(filename gi.FileName) error {
+	sets, err := OpenParamsSets(string(filename))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	ss.Params = MergeParamsSets(ss.Params, sets)
+	ss.ParamsFiles = append(ss.ParamsFiles, string(filename))
+	nms := make([]string, len(sets))
+	for i, ps := range sets {
+		nms[i] = ps.Name
+	}
+	fmt.Printf("Merged param sets: %s from: %v\n", strings.Join(nms, ", "), filename)
+	return nil
+}
+
+// EffectiveParams returns the params applied by SetParams as a single Base set:
+// the sheets of Base followed by those of the ParamSet, if any
+func (ss *Sim) EffectiveParams() params.Sets {
+	eff := &params.Set{Name: "Base", Desc: "effective params of " + ss.ParamsName(), Sheets: params.Sheets{}}
+	for _, ps := range ss.AppliedSets() {
+		for snm, sh := range ps.Sheets {
+			esh, ok := eff.Sheets[snm]
+			if !ok {
+				esh = &params.Sheet{}
+				eff.Sheets[snm] = esh
+			}
+			*esh = append(*esh, *sh...)
+		}
+	}
+	return params.Sets{eff}
+}
+
+// SaveParams saves the params currently applied by SetParams as a single Base set
+// (see EffectiveParams): as Go code initializing SavedParamsSets, to replace
+// params.go, if the file has a .go extension, TOML if .toml, otherwise JSON, which
+// can be loaded back with OpenParams
+func (ss *Sim) SaveParams(filename gi.FileName) error {
+	err := SaveParamsSets(ss.EffectiveParams(), string(filename))
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"testing"
+
+	"github.com/emer/emergent/params"
+)
+
+func TestMergeParamsSets(t *testing.T) {
+	base := params.Sets{
+		{Name: "Base", Desc: "base", Sheets: params.Sheets{
+			"Network": &params.Sheet{
+				{Sel: "Prjn", Params: params.Params{"Prjn.Learn.Lrate": "0.04"}},
+			},
+		}},
+		{Name: "Other", Sheets: params.Sheets{}},
+	}
+	sets := params.Sets{
+		{Name: "Base", Desc: "file", Sheets: params.Sheets{
+			"Network": &params.Sheet{
+				{Sel: "#CA3ToCA3", Params: params.Params{"Prjn.Learn.Lrate": "0.2"}},
+			},
+			"Sim": &params.Sheet{
+				{Sel: "Sim", Params: params.Params{"Sim.MaxEpcs": "20"}},
+			},
+		}},
+		{Name: "Extra", Sheets: params.Sheets{}},
+	}
+	mrg := MergeParamsSets(base, sets)
+	if len(mrg) != 3 || mrg[0].Name != "Base" || mrg[1].Name != "Other" || mrg[2].Name != "Extra" {
+		t.Fatalf("merged sets: %v", mrg)
+	}
+	net := *mrg[0].Sheets["Network"]
+	if len(net) != 2 || net[0].Sel != "Prjn" || net[1].Sel != "#CA3ToCA3" {
+		t.Errorf("merged Network sheet: %v", net)
+	}
+	if sim, ok := mrg[0].Sheets["Sim"]; !ok || len(*sim) != 1 {
+		t.Errorf("merged Sim sheet: %v", sim)
+	}
+	if mrg[0].Desc != "base -- file" {
+		t.Errorf("merged Desc: %s", mrg[0].Desc)
+	}
+	if mrg[1] != base[1] || mrg[2] != sets[1] {
+		t.Error("sets not in both are not kept as is")
+	}
+	if len(*base[0].Sheets["Network"]) != 1 || len(base[0].Sheets) != 1 || base[0].Desc != "base" {
+		t.Error("base sets were modified")
+	}
+}
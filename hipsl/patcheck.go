@@ -0,0 +1,103 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// CppLayout returns true if given pattern table has the legacy layout of C++
+// emergent, in which the dims of the tensor columns are reversed relative to Go,
+// e.g., <4:1,8,1,1> instead of <4:1,1,8,1> for a column of 8 units: its 4D
+// columns have all their units in the pool dims, with one unit per pool
+func CppLayout(dt *etable.Table) bool {
+	for _, cl := range dt.Cols {
+		shp := cl.Shapes() // [0] = row
+		if len(shp) == 5 && shp[3] == 1 && shp[4] == 1 && shp[1]*shp[2] > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePats checks the pattern table of given name against the layers of net
+// of given names, which have a column each: the table must have rows, a Name
+// column (with no duplicate names if uniq), and a column for each layer, whose
+// patterns fit in the layer and have values between 0 and 1.  All the problems
+// found are returned in one error.
+func ValidatePats(dt *etable.Table, tnm string, net emer.Network, lays []string, uniq bool) error {
+	var errs []string
+	if dt.Rows == 0 {
+		errs = append(errs, "has no rows")
+	}
+	if ncl, err := dt.ColByNameTry("Name"); err != nil {
+		errs = append(errs, "has no Name column")
+	} else if uniq {
+		rows := make(map[string]int)
+		for row := 0; row < dt.Rows; row++ {
+			nm := ncl.StringVal1D(row)
+			if prv, has := rows[nm]; has {
+				errs = append(errs, fmt.Sprintf("has duplicate name %q at rows %d and %d", nm, prv, row))
+				continue
+			}
+			rows[nm] = row
+		}
+	}
+	for _, lnm := range lays {
+		cl, err := dt.ColByNameTry(lnm)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("has no %s column", lnm))
+			continue
+		}
+		if cl.DataType() == etensor.STRING {
+			errs = append(errs, fmt.Sprintf("%s column is not numeric", lnm))
+			continue
+		}
+		ly, err := net.LayerByNameTry(lnm)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		nu := 0 // units per row
+		if dt.Rows > 0 {
+			nu = cl.Len() / dt.Rows
+		}
+		lshp := ly.Shape().Shp
+		cshp := cl.Shapes()[1:]
+		if len(cshp) == len(lshp) { // applied dim by dim, so each must fit
+			for i := range cshp {
+				if cshp[i] > lshp[i] {
+					errs = append(errs, fmt.Sprintf("%s column shape %v does not fit layer shape %v", lnm, cshp, lshp))
+					break
+				}
+			}
+		} else if nu > ly.Shape().Len() { // applied flat
+			errs = append(errs, fmt.Sprintf("%s column has %d units, layer has %d", lnm, nu, ly.Shape().Len()))
+		}
+		nbad := 0
+		for i := 0; i < cl.Len(); i++ {
+			v := cl.FloatVal1D(i)
+			if math.IsNaN(v) || v < 0 || v > 1 {
+				if nbad == 0 {
+					errs = append(errs, fmt.Sprintf("%s column has value %g out of range 0-1 at row %d", lnm, v, i/nu))
+				}
+				nbad++
+			}
+		}
+		if nbad > 1 {
+			errs = append(errs, fmt.Sprintf("%s column has %d values out of range 0-1 in all", lnm, nbad))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("patterns %s: %s", tnm, strings.Join(errs, "; "))
+}
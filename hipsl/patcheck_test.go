@@ -0,0 +1,106 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/leabra/leabra"
+)
+
+// newPatTable returns a table of given rows with a Name column and an Input
+// column of given cell shape, with unit i of each row set to 1
+func newPatTable(names []string, shp []int) *etable.Table {
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Input", etensor.FLOAT32, shp, nil},
+	}, len(names))
+	for row, nm := range names {
+		dt.SetCellString("Name", row, nm)
+		dt.CellTensor("Input", row).SetFloat1D(row, 1)
+	}
+	return dt
+}
+
+func TestValidatePats(t *testing.T) {
+	net := &leabra.Network{}
+	net.InitName(net, "Test")
+	net.AddLayer2D("Input", 8, 1, emer.Input)
+	lays := []string{"Input"}
+
+	dt := newPatTable([]string{"A", "B", "C"}, []int{8, 1})
+	if err := ValidatePats(dt, "ok", net, lays, true); err != nil {
+		t.Errorf("valid patterns: %v", err)
+	}
+
+	dup := newPatTable([]string{"A", "B", "A"}, []int{8, 1})
+	if err := ValidatePats(dup, "dup", net, lays, false); err != nil {
+		t.Errorf("duplicate names without uniq: %v", err)
+	}
+
+	rng := newPatTable([]string{"A", "B"}, []int{8, 1})
+	rng.CellTensor("Input", 1).SetFloat1D(2, 2)
+	rng.CellTensor("Input", 1).SetFloat1D(3, -1)
+	bad := []struct {
+		nm   string
+		dt   *etable.Table
+		uniq bool
+		errs []string
+	}{
+		{"empty", newPatTable(nil, []int{8, 1}), false, []string{"has no rows"}},
+		{"duplicate", newPatTable([]string{"A", "B", "A"}, []int{8, 1}), true, []string{`duplicate name "A" at rows 0 and 2`}},
+		{"too big", newPatTable([]string{"A"}, []int{9, 1}), false, []string{"Input column shape [9 1] does not fit layer shape [8 1]"}},
+		{"flat", newPatTable([]string{"A"}, []int{9}), false, []string{"Input column has 9 units, layer has 8"}},
+		{"range", rng, false, []string{"Input column has value 2 out of range 0-1 at row 1", "Input column has 2 values out of range"}},
+	}
+	for _, bt := range bad {
+		err := ValidatePats(bt.dt, bt.nm, net, lays, bt.uniq)
+		if err == nil {
+			t.Errorf("%s: no error", bt.nm)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), "patterns "+bt.nm+": ") {
+			t.Errorf("%s: error %q does not name the table", bt.nm, err)
+		}
+		for _, es := range bt.errs {
+			if !strings.Contains(err.Error(), es) {
+				t.Errorf("%s: error %q does not contain %q", bt.nm, err, es)
+			}
+		}
+	}
+
+	err := ValidatePats(etable.New(etable.Schema{{"Input", etensor.STRING, nil, nil}}, 1), "all", net, []string{"Input", "ECout"}, true)
+	if err == nil {
+		t.Fatal("no error for a table with no Name and no numeric columns")
+	}
+	for _, es := range []string{"has no Name column", "Input column is not numeric", "has no ECout column"} {
+		if !strings.Contains(err.Error(), es) {
+			t.Errorf("error %q does not contain %q", err, es)
+		}
+	}
+}
+
+func TestCppLayout(t *testing.T) {
+	for _, ct := range []struct {
+		shp []int
+		cpp bool
+	}{
+		{[]int{1, 8, 1, 1}, true},
+		{[]int{2, 4, 1, 1}, true},
+		{[]int{1, 1, 8, 1}, false},
+		{[]int{1, 1, 1, 1}, false},
+		{[]int{8, 1}, false},
+	} {
+		dt := newPatTable([]string{"A"}, ct.shp)
+		if cpp := CppLayout(dt); cpp != ct.cpp {
+			t.Errorf("shape %v: CppLayout %v, want %v", ct.shp, cpp, ct.cpp)
+		}
+	}
+}
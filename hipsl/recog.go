@@ -0,0 +1,185 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"math"
+	"strings"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/leabra/leabra"
+)
+
+// RecogKinds are the kinds of items of the recognition test, in the Group column
+// of its table: the studied pairs of items, and the two kinds of lures
+var RecogKinds = []string{"Target", "Across", "Novel"}
+
+// RecogPairs are the pairs of items of the recognition test, as indexes into the
+// items of the training env, by kind of RecogKinds
+type RecogPairs struct {
+	Target [][2]int `desc:"pairs of items studied together in training"`
+	Across [][2]int `desc:"across-pair pairings: lures of items of different pairs, which follow each other in the stream only across the boundary of pairs or groups, if ever"`
+	Novel  [][2]int `desc:"novel combinations: lures of items of different pairs, which never follow each other in the stream"`
+}
+
+// Kinds returns the pairs of each kind, in the order of RecogKinds
+func (rp *RecogPairs) Kinds() [][][2]int {
+	return [][][2]int{rp.Target, rp.Across, rp.Novel}
+}
+
+// ConfigRecogTable configures given table with one recognition test pattern per
+// pair of items of pairs, with the first item at prevAct and the second at curAct
+// on both Input and ECout, as presented in training, and the kind of the pair in
+// the Group column
+func ConfigRecogTable(dt *etable.Table, items []string, sz int, pairs *RecogPairs, prevAct, curAct float32) {
+	n := len(pairs.Target) + len(pairs.Across) + len(pairs.Novel)
+	shp := []int{1, 1, sz, 1}
+	sch := etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Group", etensor.STRING, nil, nil},
+		{"Input", etensor.FLOAT32, shp, nil},
+		{"ECout", etensor.FLOAT32, shp, nil},
+	}
+	dt.SetFromSchema(sch, n)
+	dt.SetMetaData("name", "Recognition Testing Patterns")
+	dt.SetMetaData("desc", "Recognition Testing Patterns: studied pairs and lures")
+	in := dt.ColByName("Input").(*etensor.Float32)
+	out := dt.ColByName("ECout").(*etensor.Float32)
+	row := 0
+	for ki, kps := range pairs.Kinds() {
+		for _, pr := range kps {
+			dt.SetCellString("Name", row, items[pr[0]]+items[pr[1]])
+			dt.SetCellString("Group", row, RecogKinds[ki])
+			in.Values[row*sz+pr[0]] = prevAct
+			in.Values[row*sz+pr[1]] = curAct
+			out.Values[row*sz+pr[0]] = prevAct
+			out.Values[row*sz+pr[1]] = curAct
+			row++
+		}
+	}
+}
+
+// SetRecog turns the recognition test on or off: when on, TestAll tests the items
+// of the TestRecog table after the TstNms tables, and the hits, false alarms and
+// d' are logged in the TstEpcLog and RunLog -- the logs are reconfigured, and the
+// Reconfigured hook called
+func (ss *Sim) SetRecog(on bool) {
+	ss.RecogOn = on
+	ss.ConfigTstEpcLog(ss.TstEpcLog)
+	ss.ConfigTstTrlLog(ss.TstTrlLog)
+	ss.ConfigRunLog(ss.RunLog)
+	ss.Reconfigured()
+}
+
+// TestNames returns the names of the tests run by TestAll: the TstNms, then Recog
+// if RecogOn
+func (ss *Sim) TestNames() []string {
+	if !ss.RecogOn {
+		return ss.TstNms
+	}
+	return append(append([]string{}, ss.TstNms...), "Recog")
+}
+
+// RecogStats computes the Match of the current test trial: the agreement between
+// ECin, as driven by the test item in the first quarter, and ECout, as recalled
+// by the end of the minus phase, which is the familiarity signal of the comparison
+// of ECin and ECout in the Norman & O'Reilly (2003) and Ketz et al. (2013) models.
+// It is the number of units active in ECin that are also active in ECout, minus
+// the number of units active in ECout that are not in ECin, divided by the number
+// active in ECin -- 1 for a perfect match, NaN if ECin has no active unit.
+func (ss *Sim) RecogStats() {
+	ecout := ss.Net.LayerByName("ECout").(leabra.LeabraLayer).AsLeabra()
+	ecin := ss.Net.LayerByName("ECin").(leabra.LeabraLayer).AsLeabra()
+	nn := ecout.Shape().Len()
+	actMi, _ := ecout.UnitVarIdx("ActM")
+	actQ1i, _ := ecin.UnitVarIdx("ActQ1")
+	inN := 0.0
+	match := 0.0
+	mismatch := 0.0
+	for ni := 0; ni < nn; ni++ {
+		in := ecin.UnitVal1D(actQ1i, ni) > 0.5
+		out := ecout.UnitVal1D(actMi, ni) > 0.5
+		if in {
+			inN += 1
+			if out {
+				match += 1
+			}
+		} else if out {
+			mismatch += 1
+		}
+	}
+	if inN == 0 {
+		ss.Match = math.NaN()
+		return
+	}
+	ss.Match = (match - mismatch) / inN
+}
+
+// RecogCounts returns the number of items of the Recog test in given TstTrlLog
+// that are judged old, i.e., whose Match is at least RecogThr, and the number of
+// items, by kind of RecogKinds, and for all the lures as Lure
+func (ss *Sim) RecogCounts(dt *etable.Table) (old, n map[string]float64) {
+	old = make(map[string]float64)
+	n = make(map[string]float64)
+	for i := 0; i < dt.Rows; i++ {
+		if dt.CellString("TestNm", i) != "Recog" {
+			continue
+		}
+		kind := dt.CellString("Group", i)
+		n[kind]++
+		if dt.CellFloat("Match", i) >= ss.RecogThr {
+			old[kind]++
+		}
+	}
+	for _, kind := range RecogKinds[1:] {
+		n["Lure"] += n[kind]
+		old["Lure"] += old[kind]
+	}
+	return
+}
+
+// DPrime returns the sensitivity d' = z(H) - z(F) of given numbers of hits out of
+// nt targets and false alarms out of nl lures, where z is the inverse of the
+// standard normal CDF, and the rates H and F have the log-linear correction of
+// Hautus (1995), (n old + 0.5) / (n + 1), so that d' is finite even when all or
+// none of the items are judged old
+func DPrime(hits, nt, fas, nl float64) float64 {
+	z := func(old, n float64) float64 {
+		p := (old + 0.5) / (n + 1)
+		return math.Sqrt2 * math.Erfinv(2*p-1)
+	}
+	return z(hits, nt) - z(fas, nl)
+}
+
+// LogRecog sets the hit rate, false alarm rate and d' of the Recog test in given
+// TstTrlLog to given row of the TstEpcLog, for all the lures, then by kind of lure
+// -- NaN for a kind with no items, e.g., the Across lures of the Community paradigm
+func (ss *Sim) LogRecog(dt *etable.Table, row int, trl *etable.Table) {
+	old, n := ss.RecogCounts(trl)
+	dt.SetCellFloat("Recog Hits", row, old["Target"]/n["Target"])
+	for _, kind := range append([]string{"Lure"}, RecogKinds[1:]...) {
+		cl := "Recog FA"
+		if kind != "Lure" {
+			cl += " " + kind
+		}
+		dpr := math.NaN()
+		if n[kind] > 0 {
+			dpr = DPrime(old["Target"], n["Target"], old[kind], n[kind])
+		}
+		dt.SetCellFloat(cl, row, old[kind]/n[kind])
+		dt.SetCellFloat(strings.Replace(cl, "FA", "DPrime", 1), row, dpr)
+	}
+}
+
+// RecogCols returns the names of the columns of the Recog test in the TstEpcLog,
+// in the order of LogRecog
+func RecogCols() []string {
+	cols := []string{"Recog Hits", "Recog FA", "Recog DPrime"}
+	for _, kind := range RecogKinds[1:] {
+		cols = append(cols, "Recog FA "+kind, "Recog DPrime "+kind)
+	}
+	return cols
+}
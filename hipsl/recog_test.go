@@ -0,0 +1,27 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDPrime(t *testing.T) {
+	for _, tc := range []struct {
+		hits, nt, fas, nl float64
+		dpr               float64
+	}{
+		{5, 10, 5, 10, 0},
+		{10, 10, 0, 10, 3.3812432591697963},
+		{8, 10, 2, 10, 1.4957171895266042},
+		{0, 10, 10, 10, -3.3812432591697963},
+	} {
+		dpr := DPrime(tc.hits, tc.nt, tc.fas, tc.nl)
+		if math.Abs(dpr-tc.dpr) > 1e-9 {
+			t.Errorf("DPrime(%g/%g, %g/%g) = %g, want %g", tc.hits, tc.nt, tc.fas, tc.nl, dpr, tc.dpr)
+		}
+	}
+}
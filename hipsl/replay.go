@@ -0,0 +1,313 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/emer/emergent/erand"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/metric"
+	"github.com/emer/leabra/leabra"
+	"github.com/goki/ki/kit"
+)
+
+// ReplayModes are the kinds of input to the network during the trials of the
+// offline replay phase
+type ReplayModes int32
+
+//go:generate stringer -type=ReplayModes -trimprefix=Replay
+
+var KiT_ReplayModes = kit.Enums.AddEnum(ReplayModesN, kit.NotBitFlag, nil)
+
+func (ev ReplayModes) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *ReplayModes) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+const (
+	// ReplayNone applies no input: the activity is spontaneous, driven only by the
+	// noise of the layers during replay, as set by Replay.ActNoise -- without any,
+	// there is no activity to replay
+	ReplayNone ReplayModes = iota
+
+	// ReplayNoise clamps Input to uniform random noise between 0 and Noise on each
+	// unit of the items
+	ReplayNoise
+
+	// ReplayCue clamps Input to a partial cue: a random item at CueAct, on top of
+	// the noise of ReplayNoise
+	ReplayCue
+
+	ReplayModesN
+)
+
+// Replay is the offline replay (sleep) phase run between training epochs: a
+// number of trials with no external input, or with noise or partial cues, run on
+// the Replay schedule of the Theta, with or without learning.  What the Layers
+// reactivate is logged in the ReplayLog, with the test items whose patterns in
+// the RSA of the last TestAll are the closest to the reactivations.
+type Replay struct {
+	On        bool         `desc:"run a replay phase between training epochs"`
+	Interval  int          `desc:"run the replay phase after every this many epochs of training, before testing"`
+	Trials    int          `desc:"number of trials (alpha cycles) of each replay phase"`
+	Mode      ReplayModes  `desc:"input during replay: None, Noise or a partial Cue"`
+	Noise     float32      `desc:"maximum of the uniform random activity of each Input unit of the items, in Noise and Cue modes"`
+	CueAct    float32      `desc:"activity of the cue item on Input in Cue mode"`
+	Learn     bool         `desc:"learn during the replay trials, as in training trials -- otherwise the weights are not changed"`
+	ActNoise  float32      `desc:"standard deviation of the Gaussian Ge noise (Act.Noise) of the NoiseLays during the replay trials, which drives the spontaneous activity of the None mode -- it is set on entering the replay phase and restored on exit, so training and testing keep the noise of their params -- 0 for none"`
+	NoiseLays []string     `desc:"layers that have the ActNoise during the replay trials"`
+	Layers    []string     `desc:"layers whose reactivations are logged and matched to the test items, which must be among the Layers of the RSA"`
+	Rand      *rand.Rand   `json:"-" view:"-" desc:"random number generator of the noise and cues, seeded with the Replay seed of the run"`
+	Src       *CountSource `json:"-" view:"-" desc:"source of Rand, which keeps track of its state for checkpoints"`
+	Vals      []float32    `json:"-" view:"-" desc:"temp slice for holding the input values"`
+	Warned    bool         `json:"-" view:"-" desc:"whether the lack of noise in the None mode has been warned about"`
+}
+
+// Defaults sets 20 trials after every epoch, driven by noise on Input, without
+// learning or layer noise
+func (rp *Replay) Defaults() {
+	rp.Interval = 1
+	rp.Trials = 20
+	rp.Mode = ReplayNoise
+	rp.Noise = 0.5
+	rp.CueAct = 1
+	rp.NoiseLays = []string{"DG", "CA3", "CA1"}
+	rp.Layers = []string{"CA3", "CA1"}
+}
+
+// SetActNoise sets the Act.Noise of the NoiseLays of given network to Gaussian
+// Ge noise with a standard deviation of ActNoise, and returns their previous
+// Act.Noise to restore with RestoreActNoise -- nil if ActNoise is 0
+func (rp *Replay) SetActNoise(net *leabra.Network) []leabra.ActNoiseParams {
+	if rp.ActNoise <= 0 {
+		return nil
+	}
+	prv := make([]leabra.ActNoiseParams, len(rp.NoiseLays))
+	for i, lnm := range rp.NoiseLays {
+		ly, err := net.LayerByNameTry(lnm)
+		if err != nil {
+			continue
+		}
+		an := &ly.(leabra.LeabraLayer).AsLeabra().Act.Noise
+		prv[i] = *an
+		an.Type = leabra.GeNoise
+		an.Dist = erand.Gaussian
+		an.Mean = 0
+		an.Var = float64(rp.ActNoise)
+	}
+	return prv
+}
+
+// RestoreActNoise restores the Act.Noise of the NoiseLays of given network to
+// that returned by SetActNoise
+func (rp *Replay) RestoreActNoise(net *leabra.Network, prv []leabra.ActNoiseParams) {
+	for i, lnm := range rp.NoiseLays {
+		if ly := net.LayerByName(lnm); ly != nil && i < len(prv) {
+			ly.(leabra.LeabraLayer).AsLeabra().Act.Noise = prv[i]
+		}
+	}
+}
+
+// SetSeed sets the Rand generator to a new one with given seed
+func (rp *Replay) SetSeed(seed int64) {
+	rp.Src = NewCountSource(seed)
+	rp.Rand = rand.New(rp.Src)
+}
+
+// ReplayPhase runs the Replay.Trials trials of a replay phase, logging each in
+// the ReplayLog, with the Replay.ActNoise in the layers
+func (ss *Sim) ReplayPhase() {
+	if ss.Replay.Mode == ReplayNone && !ss.Replay.Warned && ss.Replay.ActNoise <= 0 && NoisyLayer(ss.Net) == "" {
+		log.Println("ReplayPhase: no layer has noise to drive the spontaneous activity of the None mode, so nothing is replayed -- set Replay.ActNoise")
+		ss.Replay.Warned = true
+	}
+	if prv := ss.Replay.SetActNoise(ss.Net); prv != nil {
+		defer ss.Replay.RestoreActNoise(ss.Net, prv)
+	}
+	for trl := 0; trl < ss.Replay.Trials; trl++ {
+		ss.ReplayTrial(trl)
+		if ss.StopNow {
+			break
+		}
+	}
+}
+
+// RunReplay runs a replay phase, has stop running = false at end -- for gui
+func (ss *Sim) RunReplay() {
+	ss.StopNow = false
+	ss.ReplayPhase()
+	ss.Stopped()
+}
+
+// ReplayTrial runs given trial of the replay phase: applies the input of the
+// Replay Mode, runs an alpha cycle on the Replay schedule, learning if
+// Replay.Learn, and logs it
+func (ss *Sim) ReplayTrial(trl int) {
+	rp := &ss.Replay
+	if rp.Rand == nil {
+		rp.SetSeed(ss.Seeds.Replay)
+	}
+	ss.Net.InitExt()
+	cue := -1
+	if rp.Mode != ReplayNone {
+		input := ss.Net.LayerByName("Input").(leabra.LeabraLayer).AsLeabra()
+		nu := input.Shape().Len()
+		if len(rp.Vals) != nu {
+			rp.Vals = make([]float32, nu)
+		}
+		ni := ss.TrainEnv.PatSize()
+		for i := range rp.Vals {
+			rp.Vals[i] = 0
+			if i < ni {
+				rp.Vals[i] = rp.Noise * rp.Rand.Float32()
+			}
+		}
+		if rp.Mode == ReplayCue {
+			cue = rp.Rand.Intn(len(ss.TrainEnv.ItemNames()))
+			rp.Vals[cue] = rp.CueAct
+		}
+		input.ApplyExt1D32(rp.Vals)
+	}
+	ss.Replaying = true
+	ss.AlphaCyc(rp.Learn)
+	ss.Replaying = false
+	ss.LogReplay(ss.ReplayLog, trl, cue)
+}
+
+// ReplayItems returns the names of the items whose ECout unit is active (ActM >
+// 0.5), from the most to the least active
+func (ss *Sim) ReplayItems() []string {
+	items := ss.TrainEnv.ItemNames()
+	ecout := ss.Net.LayerByName("ECout").(leabra.LeabraLayer).AsLeabra()
+	ecout.UnitVals(&ss.TmpVals, "ActM")
+	var act []int
+	for i := range items {
+		if ss.TmpVals[i] > 0.5 {
+			act = append(act, i)
+		}
+	}
+	sort.SliceStable(act, func(i, j int) bool { return ss.TmpVals[act[i]] > ss.TmpVals[act[j]] })
+	nms := make([]string, len(act))
+	for i, it := range act {
+		nms[i] = items[it]
+	}
+	return nms
+}
+
+// RecogKind returns the kind of RecogKinds of the pair of given items, in either
+// order, as in the TestRecog table -- empty if it is not a pair of the test
+func (ss *Sim) RecogKind(a, b string) string {
+	for row := 0; row < ss.TestRecog.Rows; row++ {
+		nm := ss.TestRecog.CellString("Name", row)
+		if nm == a+b || nm == b+a {
+			return ss.TestRecog.CellString("Group", row)
+		}
+	}
+	return ""
+}
+
+// ReplayClosest returns the name of the test item whose pattern of given layer
+// in the RSA Pats of the last TestAll is the most correlated with given pattern,
+// and their correlation -- empty and NaN if the layer has no patterns, or the
+// pattern has no activity
+func (ss *Sim) ReplayClosest(lnm string, pat []float32) (string, float64) {
+	pats := ss.RSA.Pats
+	cl, err := pats.ColByNameTry(lnm)
+	if err != nil || pats.Rows == 0 {
+		return "", math.NaN()
+	}
+	active := false
+	for _, v := range pat {
+		if v > 0 {
+			active = true
+			break
+		}
+	}
+	if !active {
+		return "", math.NaN()
+	}
+	tsr := cl.(*etensor.Float32)
+	n := len(pat)
+	best, bsim := -1, 0.0
+	for row := 0; row < pats.Rows; row++ {
+		sim := float64(metric.Correlation32(pat, tsr.Values[row*n:(row+1)*n]))
+		if best < 0 || sim > bsim {
+			best, bsim = row, sim
+		}
+	}
+	return pats.CellString("Name", best), bsim
+}
+
+// LogReplay adds given trial of the replay phase, with the index of its cue item
+// (-1 if none), to the ReplayLog: the items reactivated in ECout and the kind of
+// pair of the two most active, and for each of the Replay Layers, its activity
+// (the RSA Var) and the closest test item
+func (ss *Sim) LogReplay(dt *etable.Table, trl, cue int) {
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Ctrs().Run.Cur))
+	dt.SetCellFloat("Epoch", row, float64(ss.TrainEnv.Ctrs().Epoch.Prv))
+	dt.SetCellFloat("Trial", row, float64(trl))
+	cnm := ""
+	if cue >= 0 {
+		cnm = ss.TrainEnv.ItemNames()[cue]
+	}
+	dt.SetCellString("Cue", row, cnm)
+	items := ss.ReplayItems()
+	kind := ""
+	if len(items) >= 2 {
+		kind = ss.RecogKind(items[0], items[1])
+	}
+	dt.SetCellString("ECout Items", row, strings.Join(items, " "))
+	dt.SetCellString("ECout Kind", row, kind)
+	for _, lnm := range ss.Replay.Layers {
+		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
+		tsr := ss.ValsTsr("Replay " + lnm)
+		ly.UnitValsTensor(tsr, ss.RSA.Var)
+		sum := 0.0
+		for _, v := range tsr.Values {
+			sum += float64(v)
+		}
+		dt.SetCellFloat(lnm+" "+ss.RSA.Var+".Avg", row, sum/float64(len(tsr.Values)))
+		dt.SetCellTensor(lnm+" "+ss.RSA.Var, row, tsr)
+		nm, sim := ss.ReplayClosest(lnm, tsr.Values)
+		dt.SetCellString(lnm+" Item", row, nm)
+		dt.SetCellFloat(lnm+" Sim", row, sim)
+	}
+	dt.SetCellString("Manifest", row, ss.ManifestRef())
+
+	ss.LogUpdated(dt, row)
+}
+
+func (ss *Sim) ConfigReplayLog(dt *etable.Table) {
+	dt.SetMetaData("name", "ReplayLog")
+	dt.SetMetaData("desc", "Reactivations of each trial of the replay phases of the run")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"Trial", etensor.INT64, nil, nil},
+		{"Cue", etensor.STRING, nil, nil},
+		{"ECout Items", etensor.STRING, nil, nil},
+		{"ECout Kind", etensor.STRING, nil, nil},
+	}
+	for _, lnm := range ss.Replay.Layers {
+		ly := ss.Net.LayerByName(lnm)
+		sch = append(sch, etable.Column{lnm + " " + ss.RSA.Var + ".Avg", etensor.FLOAT64, nil, nil})
+		sch = append(sch, etable.Column{lnm + " Item", etensor.STRING, nil, nil})
+		sch = append(sch, etable.Column{lnm + " Sim", etensor.FLOAT64, nil, nil})
+		sch = append(sch, etable.Column{lnm + " " + ss.RSA.Var, etensor.FLOAT32, ly.Shape().Shp, nil})
+	}
+	sch = append(sch, etable.Column{"Manifest", etensor.STRING, nil, nil})
+	dt.SetFromSchema(sch, 0)
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"log"
+	"math"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/metric"
+	"github.com/emer/etable/simat"
+)
+
+// RSA does representational similarity analysis of layer activity over the test items:
+// the Var activity of each layer is recorded for each item during testing, and then
+// Compute builds the item x item correlation matrix per layer, from which Sims gives
+// the mean similarity of items within the same group (e.g., pair) vs. across groups.
+// This replaces the offline analysis of the activity files written by AlphaCyc
+// that was used for the pattern similarity figures in results.
+type RSA struct {
+	Var     string                   `desc:"unit variable to record for each test item, e.g., ActM"`
+	Layers  []string                 `desc:"names of layers to analyze"`
+	Pats    *etable.Table            `view:"no-inline" desc:"recorded Var patterns, one row per test item, with the Name and Group of the item and a column per layer"`
+	SimMats map[string]*simat.SimMat `view:"no-inline" desc:"item x item correlation matrix for each layer, from the last Compute"`
+	Run     int                      `inactive:"+" desc:"run in which the Pats were recorded"`
+	Epoch   int                      `inactive:"+" desc:"training epoch after which the Pats were recorded -- -1 if none yet"`
+}
+
+// Defaults sets default params
+func (rs *RSA) Defaults() {
+	rs.Var = "ActM"
+	rs.Layers = []string{"ECin", "DG", "CA3", "CA1"}
+}
+
+// Init configures the Pats table for the Layers of given network, and clears the SimMats
+func (rs *RSA) Init(net emer.Network) {
+	if rs.Pats == nil {
+		rs.Pats = &etable.Table{}
+	}
+	rs.Pats.SetMetaData("name", "RSAPats")
+	rs.Pats.SetMetaData("desc", "Layer activity patterns per test item, for RSA")
+	sch := etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Group", etensor.STRING, nil, nil},
+	}
+	for _, lnm := range rs.Layers {
+		ly := net.LayerByName(lnm)
+		sch = append(sch, etable.Column{lnm, etensor.FLOAT32, ly.Shape().Shp, nil})
+	}
+	rs.Pats.SetFromSchema(sch, 0)
+	rs.Run, rs.Epoch = -1, -1
+	rs.SimMats = make(map[string]*simat.SimMat)
+	for _, lnm := range rs.Layers {
+		rs.SimMats[lnm] = &simat.SimMat{}
+		rs.SimMats[lnm].Init()
+	}
+}
+
+// Reset clears the recorded patterns, to start a new test after given
+// training epoch of given run
+func (rs *RSA) Reset(run, epc int) {
+	rs.Pats.SetNumRows(0)
+	rs.Run, rs.Epoch = run, epc
+}
+
+// Record records the current Var activity of the Layers of given network,
+// for the test item of given name and group
+func (rs *RSA) Record(net emer.Network, name, group string) {
+	row := rs.Pats.Rows
+	rs.Pats.SetNumRows(row + 1)
+	rs.Pats.SetCellString("Name", row, name)
+	rs.Pats.SetCellString("Group", row, group)
+	tsr := &etensor.Float32{}
+	for _, lnm := range rs.Layers {
+		ly := net.LayerByName(lnm)
+		err := ly.UnitValsTensor(tsr, rs.Var)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		rs.Pats.SetCellTensor(lnm, row, tsr)
+	}
+}
+
+// Compute computes the item x item correlation matrix of each layer from the recorded patterns
+func (rs *RSA) Compute() {
+	if rs.Pats.Rows < 2 {
+		return
+	}
+	ix := etable.NewIdxView(rs.Pats)
+	for _, lnm := range rs.Layers {
+		err := rs.SimMats[lnm].TableCol(ix, lnm, "Name", false, metric.Correlation64)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// Sims returns the mean correlation in the SimMat of given layer between items
+// of the same Group (within) and of different Groups (across) -- NaN if the SimMat
+// is not of the recorded patterns, or if there are no pairs of items of the kind
+func (rs *RSA) Sims(lnm string) (within, across float64) {
+	smat, ok := rs.SimMats[lnm]
+	n := rs.Pats.Rows
+	if !ok || smat.Mat == nil || smat.Mat.Len() != n*n {
+		return math.NaN(), math.NaN()
+	}
+	sm := smat.Mat
+	nw, na := 0, 0
+	for i := 0; i < n; i++ {
+		gi := rs.Pats.CellString("Group", i)
+		for j := i + 1; j < n; j++ {
+			sim := sm.FloatVal([]int{i, j})
+			if gi == rs.Pats.CellString("Group", j) {
+				within += sim
+				nw++
+			} else {
+				across += sim
+				na++
+			}
+		}
+	}
+	if nw > 0 {
+		within /= float64(nw)
+	} else {
+		within = math.NaN()
+	}
+	if na > 0 {
+		across /= float64(na)
+	} else {
+		across = math.NaN()
+	}
+	return
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"math"
+	"testing"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/simat"
+)
+
+// newTestRSA returns an RSA of a layer L of 4 units with given patterns of
+// given items and groups
+func newTestRSA(names, groups []string, pats [][]float32) *RSA {
+	rs := &RSA{Var: "ActM", Layers: []string{"L"}}
+	rs.Pats = &etable.Table{}
+	rs.Pats.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Group", etensor.STRING, nil, nil},
+		{"L", etensor.FLOAT32, []int{4}, nil},
+	}, len(names))
+	for i := range names {
+		rs.Pats.SetCellString("Name", i, names[i])
+		rs.Pats.SetCellString("Group", i, groups[i])
+		rs.Pats.SetCellTensor("L", i, etensor.NewFloat32Shape(etensor.NewShape([]int{4}, nil, nil), pats[i]))
+	}
+	rs.SimMats = map[string]*simat.SimMat{"L": {}}
+	rs.SimMats["L"].Init()
+	return rs
+}
+
+func TestRSASims(t *testing.T) {
+	rs := newTestRSA([]string{"A", "B", "C", "D"}, []string{"AB", "AB", "CD", "CD"}, [][]float32{
+		{1, 1, 0, 0},
+		{1, 0.9, 0, 0.1},
+		{0, 0, 1, 1},
+		{0.1, 0, 0.9, 1},
+	})
+	rs.Compute()
+	within, across := rs.Sims("L")
+	// by hand: the correlation of the two items of each group, and across
+	cab := corr(t, []float64{1, 1, 0, 0}, []float64{1, 0.9, 0, 0.1})
+	ccd := corr(t, []float64{0, 0, 1, 1}, []float64{0.1, 0, 0.9, 1})
+	if want := (cab + ccd) / 2; math.Abs(within-want) > 1e-6 {
+		t.Errorf("within = %g, want %g", within, want)
+	}
+	pats := [][]float64{{1, 1, 0, 0}, {1, 0.9, 0, 0.1}, {0, 0, 1, 1}, {0.1, 0, 0.9, 1}}
+	want := 0.0
+	for _, i := range []int{0, 1} {
+		for _, j := range []int{2, 3} {
+			want += corr(t, pats[i], pats[j]) / 4
+		}
+	}
+	if math.Abs(across-want) > 1e-6 {
+		t.Errorf("across = %g, want %g", across, want)
+	}
+
+	if w, a := rs.Sims("M"); !math.IsNaN(w) || !math.IsNaN(a) {
+		t.Errorf("Sims of a layer not in the RSA = %g, %g, want NaN", w, a)
+	}
+	rs.Pats.SetNumRows(3) // SimMat is not of the patterns
+	if w, a := rs.Sims("L"); !math.IsNaN(w) || !math.IsNaN(a) {
+		t.Errorf("Sims of a stale SimMat = %g, %g, want NaN", w, a)
+	}
+
+	rs = newTestRSA([]string{"A", "B"}, []string{"AB", "AB"}, [][]float32{{1, 1, 0, 0}, {1, 0.9, 0, 0.1}})
+	rs.Compute()
+	if w, a := rs.Sims("L"); math.Abs(w-cab) > 1e-6 || !math.IsNaN(a) {
+		t.Errorf("Sims of one group = %g, %g, want %g, NaN", w, a, cab)
+	}
+}
+
+// corr returns the Pearson correlation of a and b
+func corr(t *testing.T, a, b []float64) float64 {
+	t.Helper()
+	n := float64(len(a))
+	var ma, mb float64
+	for i := range a {
+		ma += a[i] / n
+		mb += b[i] / n
+	}
+	var sab, saa, sbb float64
+	for i := range a {
+		sab += (a[i] - ma) * (b[i] - mb)
+		saa += (a[i] - ma) * (a[i] - ma)
+		sbb += (b[i] - mb) * (b[i] - mb)
+	}
+	return sab / math.Sqrt(saa*sbb)
+}
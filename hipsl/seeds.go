@@ -0,0 +1,106 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"hash/fnv"
+	"math/rand"
+)
+
+// indexes of the random streams of a run, derived from the run seed by DeriveSeed
+const (
+	ConnStream int64 = iota
+	WtsStream
+	EnvStream
+	ReplayStream
+)
+
+// RunSeeds are the random seeds of one run, all derived from the master seed of
+// the Sim and the run number, so that any single run can be replayed exactly
+// from the master seed and its run number alone.  Each stream has its own seed,
+// so that e.g., changing the environment does not change the initial weights.
+type RunSeeds struct {
+	Run    int64 `inactive:"+" desc:"seed of the run, derived from the master seed and run number -- the other seeds are derived from it"`
+	Conn   int64 `inactive:"+" desc:"seed of the random connectivity of the UnifRnd projections (ECin -> DG, ECin -> CA3, DG -> CA3, CA3 -> CA1) -- each derives its own seed from it by PrjnSeed"`
+	Wts    int64 `inactive:"+" desc:"seed of the initial random weights"`
+	Env    int64 `inactive:"+" desc:"seed of the sampling of the training environment"`
+	Replay int64 `inactive:"+" desc:"seed of the noise and cues of the replay phases"`
+}
+
+// Set derives the seeds of given run from given master seed
+func (rs *RunSeeds) Set(master int64, run int) {
+	rs.Run = DeriveSeed(master, int64(run))
+	rs.Conn = DeriveSeed(rs.Run, ConnStream)
+	rs.Wts = DeriveSeed(rs.Run, WtsStream)
+	rs.Env = DeriveSeed(rs.Run, EnvStream)
+	rs.Replay = DeriveSeed(rs.Run, ReplayStream)
+}
+
+// DeriveSeed returns a seed derived deterministically from given seed and
+// stream index, using the SplitMix64 mixing function so that neighboring seeds
+// and streams give uncorrelated seeds.  The result is non-negative and less
+// than 2^53, so it is exactly represented in float64 logs.
+func DeriveSeed(seed, stream int64) int64 {
+	z := uint64(seed) + uint64(stream+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z ^= z >> 31
+	return int64(z >> 11)
+}
+
+// PrjnSeed returns the seed of the random connectivity of the projection of given
+// name, derived from given connectivity seed by DeriveSeed with a hash of the name
+// as the stream, so that each projection has its own, whatever the topology
+func PrjnSeed(conn int64, name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return DeriveSeed(conn, int64(h.Sum64()>>1))
+}
+
+// RandState is the state of a CountSource: its seed and the number of values
+// drawn from it since seeding
+type RandState struct {
+	Seed int64
+	N    uint64
+}
+
+// CountSource is a rand.Source that counts the values drawn from it, so that its
+// state can be saved as a RandState in checkpoints, and restored by drawing the
+// same number of values again -- the math/rand sources do not expose their state.
+type CountSource struct {
+	State RandState
+	src   rand.Source64
+}
+
+// NewCountSource returns a new CountSource with given seed
+func NewCountSource(seed int64) *CountSource {
+	cs := &CountSource{src: rand.NewSource(seed).(rand.Source64)}
+	cs.State.Seed = seed
+	return cs
+}
+
+func (cs *CountSource) Seed(seed int64) {
+	cs.src.Seed(seed)
+	cs.State = RandState{Seed: seed}
+}
+
+func (cs *CountSource) Int63() int64 {
+	cs.State.N++
+	return cs.src.Int63()
+}
+
+func (cs *CountSource) Uint64() uint64 {
+	cs.State.N++
+	return cs.src.Uint64()
+}
+
+// SetState restores given state, by seeding with its Seed and drawing N values
+func (cs *CountSource) SetState(st RandState) {
+	cs.Seed(st.Seed)
+	for i := uint64(0); i < st.N; i++ {
+		cs.src.Uint64()
+	}
+	cs.State.N = st.N
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/emer/leabra/leabra"
+)
+
+func TestDeriveSeed(t *testing.T) {
+	seen := map[int64]bool{}
+	for _, seed := range []int64{0, 1, 2, -1, 1 << 62} {
+		for stream := int64(0); stream < 4; stream++ {
+			s := DeriveSeed(seed, stream)
+			if s != DeriveSeed(seed, stream) {
+				t.Errorf("DeriveSeed(%d, %d) is not deterministic", seed, stream)
+			}
+			if s < 0 || s >= 1<<53 {
+				t.Errorf("DeriveSeed(%d, %d) = %d out of [0, 2^53)", seed, stream, s)
+			}
+			if seen[s] {
+				t.Errorf("DeriveSeed(%d, %d) = %d repeats a seed", seed, stream, s)
+			}
+			seen[s] = true
+		}
+	}
+}
+
+func TestRunSeeds(t *testing.T) {
+	var r0, r1 RunSeeds
+	r0.Set(1, 0)
+	r1.Set(1, 1)
+	if r0 == r1 {
+		t.Error("runs 0 and 1 have the same seeds")
+	}
+	ss := []int64{r0.Conn, r0.Wts, r0.Env, r0.Replay}
+	for i := range ss {
+		for j := i + 1; j < len(ss); j++ {
+			if ss[i] == ss[j] {
+				t.Errorf("streams %d and %d have the same seed", i, j)
+			}
+		}
+	}
+	var r2 RunSeeds
+	r2.Set(1, 1)
+	if r1 != r2 {
+		t.Error("run 1 seeds are not reproducible")
+	}
+}
+
+// prjnConns returns the indexes of the senders of each of the first n receiving
+// units of given projection of the Sim
+func prjnConns(t *testing.T, ss *Sim, pnm string, n int) [][]int32 {
+	t.Helper()
+	for _, ly := range ss.Net.Layers {
+		for _, p := range ly.(leabra.LeabraLayer).AsLeabra().RcvPrjns {
+			if p.Name() != pnm {
+				continue
+			}
+			pj := p.(leabra.LeabraPrjn).AsLeabra()
+			cons := make([][]int32, n)
+			for ri := range cons {
+				st := pj.RConIdxSt[ri]
+				cons[ri] = append([]int32(nil), pj.RConIdx[st:st+pj.RConN[ri]]...)
+			}
+			return cons
+		}
+	}
+	t.Fatalf("no projection %s", pnm)
+	return nil
+}
+
+func TestPrjnSeeds(t *testing.T) {
+	chdirRoot(t)
+	conns := func(run int) (dg, ca3 [][]int32) {
+		cfg := DefaultConfig()
+		cfg.StartRun = run
+		ss, err := New(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return prjnConns(t, ss, "ECinToDG", 80), prjnConns(t, ss, "ECinToCA3", 80)
+	}
+	dg, ca3 := conns(0)
+	if reflect.DeepEqual(dg, ca3) {
+		t.Error("ECinToDG and ECinToCA3 have the same senders")
+	}
+	dg2, ca32 := conns(0)
+	if !reflect.DeepEqual(dg, dg2) || !reflect.DeepEqual(ca3, ca32) {
+		t.Error("connectivity of run 0 is not reproducible")
+	}
+	dg1, _ := conns(1)
+	if reflect.DeepEqual(dg, dg1) {
+		t.Error("runs 0 and 1 have the same ECinToDG connectivity")
+	}
+	if PrjnSeed(1, "ECinToDG") == PrjnSeed(1, "ECinToCA3") || PrjnSeed(1, "ECinToDG") != PrjnSeed(1, "ECinToDG") {
+		t.Error("PrjnSeed is not distinct per projection or not deterministic")
+	}
+}
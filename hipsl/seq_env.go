@@ -0,0 +1,121 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"math/rand"
+
+	"github.com/emer/emergent/env"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// SeqEnv is an env.Env that presents a stream of items over trials, such as
+// PairEnv and GraphEnv.  Sim.TrainEnv is a SeqEnv, so that the training paradigm
+// can be switched while the Sim accesses the counters through Ctrs.
+type SeqEnv interface {
+	env.Env
+
+	// Ctrs returns the Run, Epoch, Trial counters and trial name of the env
+	Ctrs() *SeqCtrs
+
+	// PatSize returns the number of units in the Input / ECout patterns
+	PatSize() int
+
+	// ItemNames returns the names of the items in the stream -- item i is
+	// represented by unit i in the patterns
+	ItemNames() []string
+
+	// ConfigRecogTable configures given table with the studied pairs of items and
+	// the lures of the recognition test (see ConfigRecogTable)
+	ConfigRecogTable(dt *etable.Table)
+}
+
+// SeqCtrs holds the counter state shared by all SeqEnv environments, which
+// embed it to get the standard Name, Desc, Counter and Action methods.
+type SeqCtrs struct {
+	Nm        string           `desc:"name of this environment"`
+	Dsc       string           `desc:"description of this environment"`
+	Run       env.Ctr          `view:"inline" desc:"current run of model as provided during Init"`
+	Epoch     env.Ctr          `view:"inline" desc:"number of times through Trial.Max trials"`
+	Trial     env.Ctr          `view:"inline" desc:"trial is the step counter within epoch"`
+	TrialName env.CurPrvString `desc:"name of the current trial"`
+	Rand      *rand.Rand       `json:"-" view:"-" desc:"random number generator of the env, for reproducible sampling independent of the global generator -- set by SetSeed, otherwise seeded from the global generator at first Init"`
+	Src       *CountSource     `json:"-" view:"-" desc:"source of Rand, which keeps track of its state for checkpoints"`
+}
+
+func (ev *SeqCtrs) Name() string   { return ev.Nm }
+func (ev *SeqCtrs) Desc() string   { return ev.Dsc }
+func (ev *SeqCtrs) Ctrs() *SeqCtrs { return ev }
+
+// SetSeed sets the Rand generator of the env to a new one with given seed --
+// call before Init to get the same stream of trials from Init on
+func (ev *SeqCtrs) SetSeed(seed int64) {
+	ev.Src = NewCountSource(seed)
+	ev.Rand = rand.New(ev.Src)
+}
+
+// InitCtrs initializes the counters for a new run
+func (ev *SeqCtrs) InitCtrs(run int) {
+	if ev.Rand == nil {
+		GlobalRandMu.Lock()
+		seed := rand.Int63()
+		GlobalRandMu.Unlock()
+		ev.SetSeed(seed)
+	}
+	ev.Run.Scale = env.Run
+	ev.Epoch.Scale = env.Epoch
+	ev.Trial.Scale = env.Trial
+	ev.Run.Init()
+	ev.Epoch.Init()
+	ev.Trial.Init()
+	ev.Run.Cur = run
+	ev.Trial.Cur = -1 // init state -- key so that first Step() = 0
+}
+
+// StepCtrs increments the Trial counter, and the Epoch when Trial wraps around
+func (ev *SeqCtrs) StepCtrs() {
+	ev.Epoch.Same() // good idea to just reset all non-inner-most counters at start
+
+	if ev.Trial.Incr() { // if true, hit max, reset to 0
+		ev.Epoch.Incr()
+	}
+}
+
+func (ev *SeqCtrs) Counter(scale env.TimeScales) (cur, prv int, chg bool) {
+	switch scale {
+	case env.Run:
+		return ev.Run.Query()
+	case env.Epoch:
+		return ev.Epoch.Query()
+	case env.Trial:
+		return ev.Trial.Query()
+	}
+	return -1, -1, false
+}
+
+func (ev *SeqCtrs) Action(element string, input etensor.Tensor) {
+	// nop
+}
+
+// ItemIdx returns the index of the item of given name in items, or -1 if not found
+func ItemIdx(items []string, name string) int {
+	for i, it := range items {
+		if it == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetOverlapPattern sets pat to the graded overlap pattern of a stream:
+// the previous item at prevAct (if prev >= 0) and the current item at curAct
+func SetOverlapPattern(pat *etensor.Float32, prev, cur int, prevAct, curAct float32) {
+	pat.SetZeros()
+	if prev >= 0 {
+		pat.Values[prev] = prevAct
+	}
+	pat.Values[cur] = curAct
+}
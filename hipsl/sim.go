@@ -0,0 +1,1990 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package hipsl is the Schapiro et al., 2017 hippocampus model on the statistical learning
+// simulation: New makes a Sim of a Config, to train and test with no GUI, e.g., by
+// TrainEpoch and TestAll, and Logs returns its logs.  The hip-sl command is its GUI and
+// command-line front-end.
+package hipsl
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/env"
+	"github.com/emer/emergent/params"
+	"github.com/emer/emergent/patgen"
+	"github.com/emer/emergent/prjn"
+	"github.com/emer/etable/agg"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/split"
+	"github.com/emer/leabra/leabra"
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// LogPrec is precision for saving float values in logs
+const LogPrec = 4
+
+// Paradigms are the statistical learning paradigms the model can be trained and tested on
+type Paradigms int32
+
+//go:generate stringer -type=Paradigms
+
+var KiT_Paradigms = kit.Enums.AddEnum(ParadigmsN, kit.NotBitFlag, nil)
+
+func (ev Paradigms) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *Paradigms) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+const (
+	// Pairs is the pair structure paradigm, trained with PairEnv
+	Pairs Paradigms = iota
+
+	// Community is the community structure paradigm of Schapiro et al. (2013), trained with GraphEnv
+	Community
+
+	// AssocInf is the associative inference paradigm: training on overlapping AB and BC pairs
+	// with AssocEnv, and testing transitive A -> C completion
+	AssocInf
+
+	ParadigmsN
+)
+
+// Sim encapsulates the entire simulation model, and we define all the
+// functionality as methods on this struct.  This structure keeps all relevant
+// state information organized and available without having to pass everything around
+// as arguments to methods, and provides the core GUI interface (note the view tags
+// for the fields which provide hints to how things should be displayed).
+type Sim struct {
+	Net          *leabra.Network   `view:"no-inline"`
+	TestAB       *etable.Table     `view:"no-inline" desc:"AB testing patterns to use"`
+	TestComm     *etable.Table     `view:"no-inline" desc:"community structure testing patterns, generated from GraphEnv"`
+	TestInfAB    *etable.Table     `view:"no-inline" desc:"associative inference A -> B testing patterns, generated from AssocEnv"`
+	TestInfBC    *etable.Table     `view:"no-inline" desc:"associative inference B -> C testing patterns, generated from AssocEnv"`
+	TestInfAC    *etable.Table     `view:"no-inline" desc:"associative inference A -> C transitive testing patterns, generated from AssocEnv"`
+	TestRecog    *etable.Table     `view:"no-inline" desc:"recognition testing patterns: the studied pairs of items and the lures, generated from the TrainEnv"`
+	TrnTrlLog    *etable.Table     `view:"no-inline" desc:"training trial-level log data"`
+	TrnEpcLog    *etable.Table     `view:"no-inline" desc:"training epoch-level log data"`
+	TstEpcLog    *etable.Table     `view:"no-inline" desc:"testing epoch-level log data"`
+	TstTrlLog    *etable.Table     `view:"no-inline" desc:"testing trial-level log data"`
+	TstCycLog    *etable.Table     `view:"no-inline" desc:"testing cycle-level log data"`
+	RSALog       *etable.Table     `view:"no-inline" desc:"representational similarity analysis log: within vs. across group similarity per layer, for each test"`
+	ReplayLog    *etable.Table     `view:"no-inline" desc:"replay log: what is reactivated in each trial of the replay phases of the run"`
+	WtEpcLog     *etable.Table     `view:"no-inline" desc:"weight log: change, distribution and effective connectivity of the WtSnap projections after each epoch of training"`
+	RunLog       *etable.Table     `view:"no-inline" desc:"summary log of each run"`
+	RunStats     *etable.Table     `view:"no-inline" desc:"aggregate stats on all runs"`
+	SweepLog     *etable.Table     `view:"no-inline" desc:"summary log of each run of each point of the last parameter sweep, keyed by the values of the swept params"`
+	TstStats     *etable.Table     `view:"no-inline" desc:"testing stats"`
+	Params       params.Sets       `view:"no-inline" desc:"full collection of param sets"`
+	ParamSet     string            `desc:"which set of *additional* parameters to use -- always applies Base and optionaly this next if set"`
+	Tag          string            `desc:"extra tag string to add to any file names output from sim (e.g., weights files, log files, params)"`
+	MaxRuns      int               `desc:"maximum number of model runs to perform"`
+	StartRun     int               `desc:"run number to start at -- runs go from StartRun to MaxRuns-1, so a single run can be replayed with the same RndSeed, StartRun = run and MaxRuns = run+1"`
+	RndSeed      int64             `desc:"master random seed, from which the seeds of each run are derived -- by default the same for every Init, use New Seed for different results"`
+	Seeds        RunSeeds          `view:"inline" desc:"random seeds of the current run, derived from RndSeed and the run number"`
+	MaxEpcs      int               `desc:"maximum number of epochs to run per model run"`
+	TrialperEpc  int               `desc:"number of trials per epoch of training"`
+	NZeroStop    int               `desc:"if a positive number, training will stop after this many epochs with zero mem errors"`
+	Paradigm     Paradigms         `desc:"statistical learning paradigm to train and test on -- use SetParadigm to change, as the network and logs are reconfigured"`
+	PairEnv      PairEnv           `desc:"pair structure training environment, used for the Pairs paradigm"`
+	GraphEnv     GraphEnv          `desc:"community structure training environment, used for the Community paradigm"`
+	AssocEnv     AssocEnv          `desc:"associative inference training environment, used for the AssocInf paradigm"`
+	Topology     Topology          `view:"no-inline" desc:"architecture of the network: its layers and projections -- use OpenTopology to load from a JSON or TOML file, as the network is reconfigured"`
+	TrainEnv     SeqEnv            `view:"-" desc:"Training environment -- the env for the current Paradigm, which generates the stream of input / output patterns over training"`
+	TestEnv      env.FixedTable    `desc:"Testing environment -- manages iterating over testing"`
+	Time         leabra.Time       `desc:"leabra timing parameters and state"`
+	ViewOn       bool              `desc:"whether to update the network view while running"`
+	TrainUpdt    leabra.TimeScales `desc:"at what time scale to update the display during training?  Anything longer than Epoch updates at Epoch in this model"`
+	TestUpdt     leabra.TimeScales `desc:"at what time scale to update the display during testing?  Anything longer than Epoch updates at Epoch in this model"`
+	TestInterval int               `desc:"how often to run through all the test patterns, in terms of training epochs -- can use 0 or -1 for no testing"`
+	CkptInterval int               `desc:"how often to save a checkpoint to resume from, in terms of training epochs -- 0 for never"`
+	CkptDir      string            `desc:"directory to save checkpoints to, replacing the previous one -- if empty, named after the network and RunName"`
+	MemThr       float64           `desc:"threshold to use for memory test -- if error proportion is below this number, it is scored as a correct trial"`
+	RecogOn      bool              `inactive:"+" desc:"if true, TestAll also runs the recognition test of the TestRecog items, and logs its hits, false alarms and d' -- use SetRecog to change, as the logs are reconfigured"`
+	RecogThr     float64           `desc:"threshold of the recognition test: an item whose ECin - ECout Match is at least this is judged old"`
+	Theta        ThetaSched        `view:"no-inline" desc:"schedule of the quarters of the theta cycle for training and testing: their length, the strength of the ECin and CA3 inputs to CA1, clamping of ECout and when to compute stats -- set from the Sim sheet of the param sets"`
+	Lesions      Lesions           `inactive:"+" desc:"lesions of layers and projections, e.g., to compare the monosynaptic and trisynaptic pathways -- use SetLesions to change"`
+	RSA          RSA               `view:"no-inline" desc:"representational similarity analysis of the test items, computed at the end of each TestAll"`
+	ActRec       ActRec            `view:"no-inline" desc:"records unit activity of selected layers and variables to a file per run -- set On to use"`
+	NpyRec       NpyRec            `view:"no-inline" desc:"records unit activity of each test item at each cycle, saved with the weights to a NumPy .npz file per test -- set On to use"`
+	Replay       Replay            `view:"no-inline" desc:"offline replay phase between training epochs, with no input, noise or partial cues -- set On to use"`
+	WtSnap       WtSnap            `view:"no-inline" desc:"snapshots of the weights of projections after each epoch of training, whose stats are logged in the WtEpcLog -- set On to use"`
+	Manifest     Manifest          `view:"-" desc:"provenance record of the current run: params, seeds, files and versions -- saved at the end of the run if ManifestOn"`
+
+	// statistics: note use float64 as that is best for etable.Table
+	TestNm         string  `inactive:"+" desc:"what set of patterns are we currently testing"`
+	Mem            float64 `inactive:"+" desc:"whether current trial's ECout met memory criterion -- NaN at test if the item has no completion bits, e.g., the single items of the Pairs and Community tests, which are not scored"`
+	TrgOnWasOffAll float64 `inactive:"+" desc:"current trial's proportion of bits where target = on but ECout was off ( < 0.5), for all bits"`
+	TrgOnWasOffCmp float64 `inactive:"+" desc:"current trial's proportion of bits where target = on but ECout was off ( < 0.5), for only completion bits that were not active in ECin -- NaN if none"`
+	TrgOffWasOn    float64 `inactive:"+" desc:"current trial's proportion of bits where target = off but ECout was on ( > 0.5)"`
+	ProdSelf       float64 `inactive:"+" desc:"current test trial's probability that ECout produces the cue item itself -- NaN if the test item has no single cue"`
+	ProdPartner    float64 `inactive:"+" desc:"current test trial's probability that ECout produces the partner of the cue item (the target item for associative inference) -- NaN if none"`
+	ProdOther      float64 `inactive:"+" desc:"current test trial's probability that ECout produces any other item -- NaN if the test item has no single cue"`
+	Match          float64 `inactive:"+" desc:"current test trial's familiarity signal: the match between ECin and ECout, see RecogStats"`
+	TrlSSE         float64 `inactive:"+" desc:"current trial's sum squared error"`
+	TrlAvgSSE      float64 `inactive:"+" desc:"current trial's average sum squared error"`
+	TrlCosDiff     float64 `inactive:"+" desc:"current trial's cosine difference"`
+
+	EpcSSE        float64 `inactive:"+" desc:"last epoch's total sum squared error"`
+	EpcAvgSSE     float64 `inactive:"+" desc:"last epoch's average sum squared error (average over trials, and over units within layer)"`
+	EpcPctErr     float64 `inactive:"+" desc:"last epoch's percent of trials that had SSE > 0 (subject to .5 unit-wise tolerance)"`
+	EpcPctCor     float64 `inactive:"+" desc:"last epoch's percent of trials that had SSE == 0 (subject to .5 unit-wise tolerance)"`
+	EpcCosDiff    float64 `inactive:"+" desc:"last epoch's average cosine difference for output layer (a normalized error measure, maximum of 1 when the minus phase exactly matches the plus)"`
+	EpcPerTrlMSec float64 `inactive:"+" desc:"how long did the epoch take per trial in wall-clock milliseconds"`
+	FirstZero     int     `inactive:"+" desc:"epoch at when Mem err first went to zero"`
+	NZero         int     `inactive:"+" desc:"number of epochs in a row with zero Mem err"`
+
+	// internal state - view:"-"
+	SumSSE       float64                     `view:"-" inactive:"+" desc:"sum to increment as we go through epoch"`
+	SumAvgSSE    float64                     `view:"-" inactive:"+" desc:"sum to increment as we go through epoch"`
+	SumCosDiff   float64                     `view:"-" inactive:"+" desc:"sum to increment as we go through epoch"`
+	CntErr       int                         `view:"-" inactive:"+" desc:"sum of errs to increment as we go through epoch"`
+	TmpVals      []float32                   `view:"-" desc:"temp slice for holding values -- prevent mem allocs"`
+	ProdProbs    etensor.Float32             `view:"-" desc:"current test trial's probability of production of each item"`
+	ValsTsrs     map[string]*etensor.Float32 `view:"-" desc:"for holding layer values"`
+	LayStatNms   []string                    `view:"-" desc:"names of layers to collect more detailed stats on (avg act, etc)"`
+	TstNms       []string                    `view:"-" desc:"names of test tables"`
+	TstStatNms   []string                    `view:"-" desc:"names of test stats"`
+	NZeroWarned  bool                        `view:"-" desc:"whether NZeroStop with an unscored Mem of the first test has been warned about"`
+	SaveWts      bool                        `view:"-" desc:"for command-line run only, auto-save final weights after each run"`
+	Subjects     int                         `view:"-" desc:"for command-line run only, number of simulated subjects to do the runs in parallel with RunSubjects, if > 1"`
+	SubjTstTrls  *etable.Table               `view:"-" desc:"as a subject of RunSubjects, the TstTrlLog rows of its current run, kept if the TstTrlLog of the Sim is observed"`
+	ManifestOn   bool                        `view:"-" desc:"for command-line run only, save the Manifest of each run at its end, referenced by the Manifest column of the logs"`
+	LogFiles     []string                    `view:"-" desc:"names of the log files being saved, recorded in the Manifest"`
+	PatFiles     []string                    `view:"-" desc:"names of the pattern files opened by OpenPat, recorded in the Manifest"`
+	ParamsFiles  []string                    `view:"-" desc:"names of the params files merged into the Params by OpenParams, recorded in the Manifest"`
+	NoGui        bool                        `view:"-" desc:"if true, runing in no GUI mode"`
+	LogSetParams bool                        `view:"-" desc:"if true, print message for all params that are set"`
+	LogSubs      []LogSub                    `view:"-" desc:"subscriptions of LogObservers to the logs, e.g., plots in the GUI, or log files and progress reports with no GUI -- see Observe"`
+	Hooks        Hooks                       `view:"-" desc:"functions through which a front-end, e.g., the GUI, follows the network and running state of the Sim -- none when headless"`
+	IsRunning    bool                        `view:"-" desc:"true if sim is running"`
+	Replaying    bool                        `view:"-" desc:"true while running a trial of the replay phase, which AlphaCyc runs on the Replay schedule"`
+	StopNow      bool                        `view:"-" desc:"flag to stop running"`
+	NeedsNewRun  bool                        `view:"-" desc:"flag to initialize NewRun if last one finished"`
+	Resumed      bool                        `view:"-" desc:"flag that the state was restored from a checkpoint, so the next TrainTrial runs the trial the TrainEnv was already stepped into"`
+	LastEpcTime  time.Time                   `view:"-" desc:"timer for last epoch"`
+	// ACon      int64            `view:"-" desc:"the current random seed"`
+}
+
+// this registers this Sim Type and gives it properties that e.g.,
+// prompt for filename for save methods.
+var KiT_Sim = kit.Types.AddType(&Sim{}, SimProps)
+
+// New creates new blank elements and initializes defaults
+func (ss *Sim) New() {
+	ss.Net = &leabra.Network{}
+	ss.TestAB = &etable.Table{}
+	ss.TestComm = &etable.Table{}
+	ss.TestInfAB = &etable.Table{}
+	ss.TestInfBC = &etable.Table{}
+	ss.TestInfAC = &etable.Table{}
+	ss.TestRecog = &etable.Table{}
+	ss.TrnTrlLog = &etable.Table{}
+	ss.TrnEpcLog = &etable.Table{}
+	ss.TstEpcLog = &etable.Table{}
+	ss.TstTrlLog = &etable.Table{}
+	ss.TstCycLog = &etable.Table{}
+	ss.RSALog = &etable.Table{}
+	ss.ReplayLog = &etable.Table{}
+	ss.WtEpcLog = &etable.Table{}
+	ss.RunLog = &etable.Table{}
+	ss.RunStats = &etable.Table{}
+	ss.SweepLog = &etable.Table{}
+	//ss.Params = ParamSets
+	ss.Params = SavedParamsSets
+	ss.RndSeed = 2
+	ss.ViewOn = true
+	ss.TrainUpdt = leabra.AlphaCycle
+	ss.TestUpdt = leabra.Cycle
+	ss.TestInterval = 1
+	ss.LogSetParams = false
+	ss.MemThr = 0.34
+	ss.RecogThr = 0.75
+	ss.LayStatNms = []string{"ECin", "DG", "CA3", "CA1"}
+	ss.MaxEpcs = 10
+	ss.TrialperEpc = 80
+	ss.PairEnv.Defaults()
+	ss.GraphEnv.Defaults()
+	ss.AssocEnv.Defaults()
+	ss.Topology.Defaults()
+	ss.Theta.Defaults()
+	ss.RSA.Defaults()
+	ss.ActRec.Defaults()
+	ss.NpyRec.Defaults()
+	ss.Replay.Defaults()
+	ss.WtSnap.Defaults()
+	// ss.ACon = 0
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////
+// 		Configs
+
+// Config configures all the elements using the standard functions
+func (ss *Sim) Config() {
+	ss.OpenPats()
+	ss.ConfigEnv()
+	ss.ConfigNet(ss.Net)
+	ss.RSA.Init(ss.Net)
+	ss.ConfigTrnTrlLog(ss.TrnTrlLog)
+	ss.ConfigTrnEpcLog(ss.TrnEpcLog)
+	ss.ConfigTstEpcLog(ss.TstEpcLog)
+	ss.ConfigTstTrlLog(ss.TstTrlLog)
+	ss.ConfigTstCycLog(ss.TstCycLog)
+	ss.ConfigRSALog(ss.RSALog)
+	ss.ConfigReplayLog(ss.ReplayLog)
+	ss.ConfigWtEpcLog(ss.WtEpcLog)
+	ss.ConfigRunLog(ss.RunLog)
+}
+
+func (ss *Sim) ConfigEnv() {
+	if ss.MaxRuns == 0 { // allow user override
+		ss.MaxRuns = 10
+	}
+	if ss.MaxEpcs == 0 { // allow user override
+		ss.MaxEpcs = 12
+		ss.NZeroStop = 1
+	}
+
+	ss.TstStatNms = []string{"Mem", "TrgOnWasOff", "TrgOffWasOn"}
+	switch ss.Paradigm {
+	case Community:
+		ss.TrainEnv = &ss.GraphEnv
+		ss.TstNms = []string{"Comm"}
+	case AssocInf:
+		ss.TrainEnv = &ss.AssocEnv
+		ss.TstNms = []string{"AB", "BC", "AC"}
+		ss.TstStatNms = append(ss.TstStatNms, "ProdSelf", "ProdPartner", "ProdOther")
+	default:
+		ss.TrainEnv = &ss.PairEnv
+		ss.TstNms = []string{"AB"}
+		ss.TstStatNms = append(ss.TstStatNms, "ProdSelf", "ProdPartner", "ProdOther")
+	}
+	trn := ss.TrainEnv.Ctrs()
+	trn.Nm = "TrainEnv"
+	trn.Dsc = "training params and state"
+	if err := ss.TrainEnv.Validate(); err != nil {
+		log.Println(err)
+	}
+	trn.Run.Max = ss.MaxRuns // note: we are not setting epoch max -- do that manually
+	trn.Trial.Max = ss.TrialperEpc
+	switch ss.Paradigm {
+	case Community:
+		ss.GraphEnv.ConfigTestTable(ss.TestComm)
+	case AssocInf:
+		for _, tnm := range ss.TstNms {
+			pr := AssocProbes[tnm]
+			ss.AssocEnv.ConfigTestTable(ss.TestTable(tnm), tnm, pr[0], pr[1])
+		}
+	}
+	ss.TrainEnv.ConfigRecogTable(ss.TestRecog)
+
+	ss.TestEnv.Nm = "TestEnv"
+	ss.TestEnv.Dsc = "testing params and state"
+	ss.TestEnv.Table = etable.NewIdxView(ss.TestTable(ss.TstNms[0]))
+	ss.TestEnv.Sequential = true
+	ss.TestEnv.Validate()
+
+	ss.TrainEnv.Init(ss.StartRun)
+	ss.TestEnv.Init(ss.StartRun)
+}
+
+// TestTable returns the table of testing patterns of given name (one of TstNms,
+// or Recog) for the current Paradigm
+func (ss *Sim) TestTable(tnm string) *etable.Table {
+	if tnm == "Recog" {
+		return ss.TestRecog
+	}
+	switch ss.Paradigm {
+	case Community:
+		return ss.TestComm
+	case AssocInf:
+		switch tnm {
+		case "BC":
+			return ss.TestInfBC
+		case "AC":
+			return ss.TestInfAC
+		}
+		return ss.TestInfAB
+	}
+	return ss.TestAB
+}
+
+// NTestTrials returns the total number of testing trials over all the tables of
+// TestNames
+func (ss *Sim) NTestTrials() int {
+	nt := 0
+	for _, tnm := range ss.TestNames() {
+		nt += ss.TestTable(tnm).Rows
+	}
+	return nt
+}
+
+// SetParadigm selects the statistical learning paradigm to train and test on,
+// and reconfigures the network, environments and logs for it
+func (ss *Sim) SetParadigm(paradigm Paradigms) {
+	ss.Paradigm = paradigm
+	ss.Net = &leabra.Network{}
+	ss.Config()
+	ss.Reconfigured()
+	if !ss.NoGui {
+		ss.Init()
+	}
+}
+
+// ECSize returns the number of units in the Input and EC layers: the 12 units
+// of the original model, or more if the training patterns need them
+func (ss *Sim) ECSize() int {
+	if np := ss.TrainEnv.PatSize(); np > 12 {
+		return np
+	}
+	return 12
+}
+
+// ConfigNet builds the network from the Topology, with ECSize units in its
+// PatSize layers, and checks the layer sizes against the patterns
+func (ss *Sim) ConfigNet(net *leabra.Network) {
+	net.InitName(net, "Hip")
+	if err := ss.Topology.Validate(); err != nil {
+		log.Println(err)
+		return
+	}
+	ss.Topology.Config(net, ss.ECSize())
+	if err := ss.CheckPatSizes(net); err != nil {
+		log.Println(err)
+	}
+
+	// note: if you wanted to change a layer type from e.g., Target to Compare, do this:
+	// outLay.SetType(emer.Compare)
+	// that would mean that the output layer doesn't reflect target values in plus phase
+	// and thus removes error-driven learning -- but stats are still computed.
+
+	net.Defaults()
+	ss.SetParams("Network", ss.LogSetParams) // only set Network params
+	err := net.Build()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	net.InitWts()
+	if err := ss.Lesions.Init(net); err != nil {
+		log.Println(err)
+	}
+}
+
+// CheckPatSizes returns an error if the Input or ECout layers of net are too
+// small for the training patterns of the Paradigm, or if its testing patterns
+// are not valid for them (see ValidatePats)
+func (ss *Sim) CheckPatSizes(net *leabra.Network) error {
+	for _, lnm := range []string{"Input", "ECout"} {
+		nu := net.LayerByName(lnm).Shape().Len()
+		if np := ss.TrainEnv.PatSize(); np > nu {
+			return fmt.Errorf("CheckPatSizes: layer %s has %d units, training patterns of %v have %d", lnm, nu, ss.Paradigm, np)
+		}
+	}
+	for _, tnm := range ss.TstNms {
+		if err := ValidatePats(ss.TestTable(tnm), tnm, net, []string{"Input", "ECout"}, true); err != nil {
+			return fmt.Errorf("CheckPatSizes: %v", err)
+		}
+	}
+	return nil
+}
+
+// OpenTopology opens the Topology of the network from a JSON or TOML file, and
+// reconfigures the network for it
+func (ss *Sim) OpenTopology(filename gi.FileName) {
+	tp := Topology{}
+	if err := tp.Open(string(filename)); err != nil {
+		log.Println(err)
+		return
+	}
+	ss.Topology = tp
+	ss.SetParadigm(ss.Paradigm)
+}
+
+// SaveTopology saves the Topology of the network to a JSON or TOML file, e.g., as
+// a starting point for a variant
+func (ss *Sim) SaveTopology(filename gi.FileName) {
+	if err := ss.Topology.Save(string(filename)); err != nil {
+		log.Println(err)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// 	    Init, utils
+
+// Init restarts the run, and initializes everything, including network weights
+// and resets the epoch log table
+func (ss *Sim) Init() {
+	GlobalRandMu.Lock()
+	rand.Seed(ss.RndSeed)
+	GlobalRandMu.Unlock()
+	ss.ConfigEnv() // re-config env just in case a different set of patterns was
+	// selected or patterns have been modified etc
+	ss.StopNow = false
+	if err := ss.Lesions.Restore(ss.Net); err != nil { // params are set unlesioned
+		log.Println(err)
+	}
+	ss.SetParams("", ss.LogSetParams) // all sheets
+	ss.Manifest.RecordParams(ss.Net, ss.AppliedSets())
+	ss.NewRun()
+	ss.UpdateView(true)
+}
+
+// SetLesions sets the Lesions from a comma-separated list of Type:Name@When, e.g.,
+// ZeroWtScale:CA3ToCA1@Both,SilenceLayer:DG@Test, or a preset: MSPonly (CA3 -> CA1
+// zeroed) or TSPonly (ECin -> CA1 zeroed) -- empty for none
+func (ss *Sim) SetLesions(lesions string) error {
+	lss, err := ParseLesions(lesions)
+	if err == nil {
+		err = lss.Init(ss.Net)
+	}
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	if err := ss.Lesions.Restore(ss.Net); err != nil {
+		log.Println(err)
+	}
+	ss.Lesions = lss
+	return nil
+}
+
+// NewRndSeed gets a new master random seed based on current time -- otherwise
+// uses the same master seed, and thus the same seeds of each run, for every Init
+func (ss *Sim) NewRndSeed() {
+	ss.RndSeed = time.Now().UnixNano()
+}
+
+// Counters returns a string of the current counter state
+// use tabs to achieve a reasonable formatting overall
+// and add a few tabs at the end to allow for expansion..
+func (ss *Sim) Counters(train bool) string {
+	if train {
+		return fmt.Sprintf("Run:\t%d\tEpoch:\t%d\tTrial:\t%d\tCycle:\t%d\tName:\t%v\t\t\t", ss.TrainEnv.Ctrs().Run.Cur, ss.TrainEnv.Ctrs().Epoch.Cur, ss.TrainEnv.Ctrs().Trial.Cur, ss.Time.Cycle, ss.TrainEnv.Ctrs().TrialName.Cur)
+	} else {
+		return fmt.Sprintf("Run:\t%d\tEpoch:\t%d\tTrial:\t%d\tCycle:\t%d\tName:\t%v\t\t\t", ss.TrainEnv.Ctrs().Run.Cur, ss.TrainEnv.Ctrs().Epoch.Cur, ss.TestEnv.Trial.Cur, ss.Time.Cycle, ss.TestEnv.TrialName.Cur)
+	}
+}
+
+// UpdateView calls the ViewUpdated hook, if any, to update the view of the
+// network in training if train, else testing
+func (ss *Sim) UpdateView(train bool) {
+	if ss.Hooks.ViewUpdated != nil {
+		ss.Hooks.ViewUpdated(train)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// 	    Running the Network, starting bottom-up..
+
+// AlphaCyc runs one alpha-cycle (100 msec, 4 quarters)			 of processing,
+// following the Theta schedule of the quarters for training or testing.
+// External inputs must have already been applied prior to calling,
+// using ApplyExt method on relevant layers (see TrainTrial, TestTrial).
+// If train is true, then learning DWt or WtFmDWt calls are made.
+// Handles netview updating within scope of AlphaCycle
+func (ss *Sim) AlphaCyc(train bool) {
+	viewUpdt := ss.TrainUpdt
+	if !train {
+		viewUpdt = ss.TestUpdt
+	}
+
+	// lesions first, so that e.g., a layer silenced during testing learns again
+	if err := ss.Lesions.Apply(ss.Net, train); err != nil {
+		log.Println(err)
+	}
+
+	// update prior weight changes at start, so any DWt values remain visible at end
+	// you might want to do this less frequently to achieve a mini-batch update
+	// in which case, move it out to the TrainTrial method where the relevant
+	// counters are being dealt with.
+	if train {
+		ss.Net.WtFmDWt()
+	}
+
+	input := ss.Net.LayerByName("Input").(leabra.LeabraLayer).AsLeabra()
+	ca1 := ss.Net.LayerByName("CA1").(leabra.LeabraLayer).AsLeabra()
+	ecout := ss.Net.LayerByName("ECout").(leabra.LeabraLayer).AsLeabra()
+	ca1FmECin := ca1.SendName("ECin").(leabra.LeabraPrjn).AsLeabra()
+	ca1FmCa3 := ca1.SendName("CA3").(leabra.LeabraPrjn).AsLeabra()
+	sched := ss.Theta.Mode(train)
+	if ss.Replaying {
+		sched = &ss.Theta.Replay
+	}
+
+	if sched.ECoutTarget {
+		ecout.SetType(emer.Target) // clamp a plus phase
+	} else {
+		ecout.SetType(emer.Compare) // don't clamp
+	}
+	ecout.UpdateExtFlags() // call this after updating type
+
+	// First Quarter: during training, CA1 is driven by ECin, not by CA3 recall
+	// (which is not really active yet anyway)
+	ca1FmECin.WtScale.Abs = ss.Lesions.WtScale(ca1FmECin.Name(), train, sched.Q1.ECinCA1)
+	ca1FmCa3.WtScale.Abs = ss.Lesions.WtScale(ca1FmCa3.Name(), train, sched.Q1.CA3CA1)
+	if sched.Q1.ClampECout {
+		input.UnitVals(&ss.TmpVals, "Act")
+		ecout.ApplyExt1D32(ss.TmpVals)
+	}
+
+	ss.Net.AlphaCycInit(train)
+	ss.Time.AlphaCycStart()
+	for qtr := 0; qtr < 4; qtr++ {
+		qs := sched.Qtr(qtr)
+		for cyc := 0; cyc < qs.Cycles; cyc++ {
+			ss.Net.Cycle(&ss.Time)
+			if !train && !ss.Replaying {
+				ss.LogTstCyc(ss.TstCycLog, ss.Time.Cycle)
+			}
+			if !ss.Replaying && ss.ActRec.RecCycle(train, ss.Time.Cycle) {
+				ss.ActRec.Record(ss.Net, ss.ActRecCtrs(train), ss.Time.Cycle, -1)
+			}
+			if !train && !ss.Replaying && ss.NpyRec.On {
+				ss.NpyRec.Record(ss.Net, ss.TestEnv.TrialName.Cur, ss.TestNm, ss.Time.Cycle)
+			}
+			ss.Time.CycleInc()
+			if ss.ViewOn {
+				switch viewUpdt {
+				case leabra.Cycle:
+					if cyc != qs.Cycles-1 { // will be updated by quarter
+						ss.UpdateView(train)
+					}
+				case leabra.FastSpike:
+					if (cyc+1)%10 == 0 {
+						ss.UpdateView(train)
+					}
+				}
+			}
+
+		}
+
+		// set up the next quarter before QuarterFinal, which makes the ECout
+		// targets the external input of the plus phase
+		if qtr < 3 {
+			nq := sched.Qtr(qtr + 1)
+			// Second, Third Quarters: CA1 is driven by CA3 recall -- Fourth: back to ECin drive only
+			ecinAbs := ss.Lesions.WtScale(ca1FmECin.Name(), train, nq.ECinCA1)
+			ca3Abs := ss.Lesions.WtScale(ca1FmCa3.Name(), train, nq.CA3CA1)
+			if ecinAbs != ca1FmECin.WtScale.Abs || ca3Abs != ca1FmCa3.WtScale.Abs {
+				ca1FmECin.WtScale.Abs = ecinAbs
+				ca1FmCa3.WtScale.Abs = ca3Abs
+				ss.Net.GScaleFmAvgAct() // update computed scaling factors
+				ss.Net.InitGInc()       // scaling params change, so need to recompute all netins
+			}
+			if nq.ClampECout { // clamp ECout from Input
+				input.UnitVals(&ss.TmpVals, "Act") // Changed from input to ecin
+				ecout.ApplyExt1D32(ss.TmpVals)
+			}
+		}
+		ss.Net.QuarterFinal(&ss.Time)
+		if qs.MemStats && !ss.Replaying {
+			ss.MemStats(train) // must come after QuarterFinal
+		}
+		if !ss.Replaying && ss.ActRec.RecQuarter(train, qtr) {
+			ss.ActRec.Record(ss.Net, ss.ActRecCtrs(train), ss.Time.Cycle-1, qtr)
+		}
+		ss.Time.QuarterInc()
+		if ss.ViewOn {
+			switch {
+			case viewUpdt <= leabra.Quarter:
+				ss.UpdateView(train)
+			case viewUpdt == leabra.Phase:
+				if qtr >= 2 {
+					ss.UpdateView(train)
+				}
+			}
+		}
+	}
+
+	if train {
+		ss.Net.DWt()
+	}
+	if ss.ViewOn && viewUpdt == leabra.AlphaCycle {
+		ss.UpdateView(train)
+	}
+	if !ss.Replaying {
+		ss.ActRec.EndTrial()
+	}
+	if !train && !ss.Replaying {
+		ss.LogUpdated(ss.TstCycLog, -1) // make sure up-to-date at end
+	}
+}
+
+// ActRecCtrs returns the counter values of the current trial for the ActRec
+// records, in the order of ActRecSchema
+func (ss *Sim) ActRecCtrs(train bool) []string {
+	trn := ss.TrainEnv.Ctrs()
+	if train {
+		return []string{strconv.Itoa(trn.Run.Cur), strconv.Itoa(trn.Epoch.Cur), "Train", "", strconv.Itoa(trn.Trial.Cur), trn.TrialName.Cur, ss.ManifestRef()}
+	}
+	return []string{strconv.Itoa(trn.Run.Cur), strconv.Itoa(trn.Epoch.Prv), "Test", ss.TestNm, strconv.Itoa(ss.TestEnv.Trial.Cur), ss.TestEnv.TrialName.Cur, ss.ManifestRef()}
+}
+
+// ActRecSchema is the schema of the counter columns of the ActRec records
+var ActRecSchema = etable.Schema{
+	{"Run", etensor.INT64, nil, nil},
+	{"Epoch", etensor.INT64, nil, nil},
+	{"Phase", etensor.STRING, nil, nil},
+	{"TestNm", etensor.STRING, nil, nil},
+	{"Trial", etensor.INT64, nil, nil},
+	{"TrialName", etensor.STRING, nil, nil},
+	{"Manifest", etensor.STRING, nil, nil},
+}
+
+// ActRecFileName returns the name of the ActRec file of given run, in the ActRec Dir
+func (ss *Sim) ActRecFileName(run int) string {
+	if ss.ActRec.Arrow {
+		return ss.ArrowFileName(fmt.Sprintf("acts_run%d", run))
+	}
+	return ss.LogFileName(fmt.Sprintf("acts_run%d", run))
+}
+
+// OpenActRec opens the ActRec file for the current run, if ActRec is On
+func (ss *Sim) OpenActRec() {
+	if !ss.ActRec.On {
+		return
+	}
+	fnm := ss.ActRecFileName(ss.TrainEnv.Ctrs().Run.Cur)
+	err := ss.ActRec.Open(fnm, ss.Net, ActRecSchema)
+	if err != nil {
+		log.Println(err)
+		ss.ActRec.Close()
+	}
+}
+
+// ApplyInputs applies input patterns from given envirbonment.
+// It is good practice to have this be a separate method with appropriate
+// args so that it can be used for various different contexts
+// (training, testing, etc).
+func (ss *Sim) ApplyInputs(en env.Env) {
+	ss.Net.InitExt() // clear any existing inputs -- not strictly necessary if always
+	// going to the same layers, but good practice and cheap anyway
+
+	lays := []string{"Input", "ECout"}
+	for _, lnm := range lays {
+		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
+		pats := en.State(ly.Nm)
+		if pats != nil {
+			ly.ApplyExt(pats)
+		}
+	}
+}
+
+// TrainTrial runs one trial of training using TrainEnv
+func (ss *Sim) TrainTrial() {
+
+	// DS: Added to have  testing epoch before first epoch of training
+	if ss.TrainEnv.Ctrs().Epoch.Cur == 0 && ss.TrainEnv.Ctrs().Trial.Cur == 0 {
+		ss.TestAll()
+	}
+
+	if ss.NeedsNewRun {
+		ss.NewRun()
+	}
+
+	if ss.Resumed {
+		ss.Resumed = false
+		ss.TrainCurTrial()
+		return
+	}
+
+	ss.TrainEnv.Step() // the Env encapsulates and manages all counter state
+
+	// Key to query counters FIRST because current state is in NEXT epoch
+	// if epoch counter has changed
+	epc, _, chg := ss.TrainEnv.Counter(env.Epoch)
+	if chg {
+		ss.LogTrnEpc(ss.TrnEpcLog)
+		if ss.ViewOn && ss.TrainUpdt > leabra.AlphaCycle {
+			ss.UpdateView(true)
+		}
+		if ss.Replay.On && ss.Replay.Interval > 0 && epc%ss.Replay.Interval == 0 {
+			ss.ReplayPhase() // offline, before testing its effects
+		}
+		if ss.TestInterval > 0 && epc%ss.TestInterval == 0 { // note: epc is *next* so won't trigger first time
+			ss.TestAll()
+		}
+		if ss.WtSnap.On {
+			ss.LogWtEpc(ss.WtEpcLog) // after testing, for the RSA patterns of the items
+		}
+
+		learned := (ss.NZeroStop > 0 && ss.NZero >= ss.NZeroStop)
+
+		// if ss.TrainEnv.Table.Table == ss.TrainAB && (learned || epc == ss.MaxEpcs/2) {
+		// 	ss.TrainEnv.Table = etable.NewIdxView(ss.TrainAC)
+		// 	learned = false
+		// }
+		if learned || epc >= ss.MaxEpcs { // done with training..
+			ss.RunEnd()
+			if ss.TrainEnv.Ctrs().Run.Incr() { // we are done!
+				ss.StopNow = true
+				return
+			} else {
+				ss.NeedsNewRun = true
+				return
+			}
+		}
+		if ss.CkptInterval > 0 && epc%ss.CkptInterval == 0 {
+			if err := ss.SaveCkpt(); err != nil {
+				log.Println(err)
+			}
+		}
+	}
+
+	ss.TrainCurTrial()
+}
+
+// TrainCurTrial runs the current trial of TrainEnv, after it has been stepped
+func (ss *Sim) TrainCurTrial() {
+	ss.ApplyInputs(ss.TrainEnv)
+	ss.AlphaCyc(true)   // train
+	ss.TrialStats(true) // accumulate
+	ss.LogTrnTrl(ss.TrnTrlLog)
+}
+
+// RunEnd is called at the end of a run -- save weights, record final log, etc here
+func (ss *Sim) RunEnd() {
+	ss.LogRun(ss.RunLog)
+	ss.ActRec.Close()
+	if ss.SaveWts {
+		fnm := ss.WeightsFileName()
+		fmt.Printf("Saving Weights to: %v\n", fnm)
+		ss.Net.SaveWtsJSON(gi.FileName(fnm))
+	}
+	if ss.ManifestOn {
+		fmt.Printf("Saving manifest to: %v\n", ss.Manifest.File)
+		if err := ss.SaveManifest(); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// NewRun intializes a new run of the model, using the TrainEnv.Run counter
+// for the new run value
+func (ss *Sim) NewRun() {
+	run := ss.TrainEnv.Ctrs().Run.Cur
+	ss.Seeds.Set(ss.RndSeed, run)
+	ss.TrainEnv.Ctrs().SetSeed(ss.Seeds.Env)
+	ss.TrainEnv.Init(run)
+	ss.TestEnv.Init(run)
+	ss.Time.Reset()
+	ss.InitStats()
+	ss.TrnTrlLog.SetNumRows(0)
+	ss.TrnEpcLog.SetNumRows(0)
+	ss.TstEpcLog.SetNumRows(0)
+	ss.RSALog.SetNumRows(0)
+	ss.ReplayLog.SetNumRows(0)
+	ss.WtEpcLog.SetNumRows(0)
+	ss.Replay.SetSeed(ss.Seeds.Replay)
+	ss.NeedsNewRun = false
+
+	// each random projection gets its own seed of the run, e.g., the perforant path
+	// and mossy fibers, so that those from the same layer have different senders
+	for _, ly := range ss.Net.Layers {
+		for _, pj := range ly.(leabra.LeabraLayer).AsLeabra().RcvPrjns {
+			if ur, ok := pj.Pattern().(*prjn.UnifRnd); ok {
+				ur.RndSeed = PrjnSeed(ss.Seeds.Conn, pj.Name())
+				pj.Build()
+			}
+		}
+	}
+
+	GlobalRandMu.Lock() // weights are initialized from the global generator
+	rand.Seed(ss.Seeds.Wts)
+	ss.Net.InitWts()
+	GlobalRandMu.Unlock()
+	ss.WtSnap.Snap(ss.WtSnapPrjns())
+
+	ss.TrainEnv.Ctrs().Trial.Max = ss.TrialperEpc // DS added
+	ss.StartManifest()
+	ss.OpenActRec()
+
+}
+
+// InitStats initializes all the statistics, especially important for the
+// cumulative epoch stats -- called at start of new run
+func (ss *Sim) InitStats() {
+	// accumulators
+	ss.SumSSE = 0
+	ss.SumAvgSSE = 0
+	ss.SumCosDiff = 0
+	ss.CntErr = 0
+	ss.FirstZero = -1
+	ss.NZero = 0
+	// clear rest just to make Sim look initialized
+	ss.Mem = 0
+	ss.TrgOnWasOffAll = 0
+	ss.TrgOnWasOffCmp = 0
+	ss.TrgOffWasOn = 0
+	ss.TrlSSE = 0
+	ss.TrlAvgSSE = 0
+	ss.EpcSSE = 0
+	ss.EpcAvgSSE = 0
+	ss.EpcPctErr = 0
+	ss.EpcCosDiff = 0
+}
+
+// MemStats computes ActM vs. Target on ECout with binary counts
+// must be called at end of 3rd quarter so that Targ values are
+// for the entire full pattern as opposed to the plus-phase target
+// values clamped from ECin activations
+func (ss *Sim) MemStats(train bool) {
+	ecout := ss.Net.LayerByName("ECout").(leabra.LeabraLayer).AsLeabra()
+	ecin := ss.Net.LayerByName("ECin").(leabra.LeabraLayer).AsLeabra()
+	nn := ecout.Shape().Len()
+	trgOnWasOffAll := 0.0 // all units
+	trgOnWasOffCmp := 0.0 // only those that required completion, missing in ECin
+	trgOffWasOn := 0.0    // should have been off
+	cmpN := 0.0           // completion target
+	trgOnN := 0.0
+	trgOffN := 0.0
+	actMi, _ := ecout.UnitVarIdx("ActM")
+	targi, _ := ecout.UnitVarIdx("Targ")
+	actQ1i, _ := ecout.UnitVarIdx("ActQ1")
+	for ni := 0; ni < nn; ni++ {
+		actm := ecout.UnitVal1D(actMi, ni)
+		trg := ecout.UnitVal1D(targi, ni) // full pattern target
+		inact := ecin.UnitVal1D(actQ1i, ni)
+		if trg < 0.5 { // trgOff
+			trgOffN += 1
+			if actm > 0.5 {
+				trgOffWasOn += 1
+			}
+		} else { // trgOn
+			trgOnN += 1
+			if inact < 0.5 { // missing in ECin -- completion target
+				cmpN += 1
+				if actm < 0.5 {
+					trgOnWasOffAll += 1
+					trgOnWasOffCmp += 1
+				}
+			} else {
+				if actm < 0.5 {
+					trgOnWasOffAll += 1
+				}
+			}
+		}
+	}
+	trgOnWasOffAll /= trgOnN
+	trgOffWasOn /= trgOffN
+	if train { // no cmp
+		if trgOnWasOffAll < ss.MemThr && trgOffWasOn < ss.MemThr {
+			ss.Mem = 1
+		} else {
+			ss.Mem = 0
+		}
+	} else { // test
+		if cmpN > 0 {
+			trgOnWasOffCmp /= cmpN
+			if trgOnWasOffCmp < ss.MemThr && trgOffWasOn < ss.MemThr {
+				ss.Mem = 1
+			} else {
+				ss.Mem = 0
+			}
+		} else { // nothing to complete, e.g., the single nodes of the Community test
+			trgOnWasOffCmp = math.NaN()
+			ss.Mem = math.NaN()
+		}
+	}
+	ss.TrgOnWasOffAll = trgOnWasOffAll
+	ss.TrgOnWasOffCmp = trgOnWasOffCmp
+	ss.TrgOffWasOn = trgOffWasOn
+}
+
+// ProdStats computes the probability of production of each item by ECout at test:
+// ECout ActM is scored against the pattern of every candidate item, i.e., the
+// activity of the unit representing the item, and the scores are normalized to
+// sum to 1 over items.  These are then summed into the probability of producing
+// the cue item itself, its partner, and any other item.
+func (ss *Sim) ProdStats() {
+	items := ss.TrainEnv.ItemNames()
+	ni := len(items)
+	ss.ProdProbs.SetShape([]int{ni}, nil, nil)
+	ss.ProdProbs.SetZeros()
+	ss.ProdSelf = math.NaN()
+	ss.ProdPartner = math.NaN()
+	ss.ProdOther = math.NaN()
+
+	ecout := ss.Net.LayerByName("ECout").(leabra.LeabraLayer).AsLeabra()
+	ecout.UnitVals(&ss.TmpVals, "ActM")
+	sum := float32(0)
+	for i := 0; i < ni; i++ {
+		sum += ss.TmpVals[i]
+	}
+	if sum == 0 { // nothing produced
+		return
+	}
+	for i := 0; i < ni; i++ {
+		ss.ProdProbs.Values[i] = ss.TmpVals[i] / sum
+	}
+
+	cue, partner := ss.ProdCue()
+	if cue < 0 {
+		return
+	}
+	ss.ProdSelf = float64(ss.ProdProbs.Values[cue])
+	ss.ProdOther = 1 - ss.ProdSelf
+	if partner >= 0 {
+		ss.ProdPartner = float64(ss.ProdProbs.Values[partner])
+		ss.ProdOther -= ss.ProdPartner
+	}
+}
+
+// ProdCue returns the indexes of the single cue item of the current test trial,
+// and of its partner to be produced, for ProdStats -- -1 if none
+func (ss *Sim) ProdCue() (cue, partner int) {
+	switch ss.Paradigm {
+	case Community:
+		return ItemIdx(ss.GraphEnv.Nodes, ss.TestEnv.TrialName.Cur), -1
+	case AssocInf:
+		return ss.AssocEnv.ProbeItems(ss.TestEnv.GroupName.Cur, ss.TestNm)
+	}
+	return ss.PairEnv.Partner(ss.TestEnv.TrialName.Cur)
+}
+
+// TrialStats computes the trial-level statistics and adds them to the epoch accumulators if
+// accum is true.  Note that we're accumulating stats here on the Sim side so the
+// core algorithm side remains as simple as possible, and doesn't need to worry about
+// different time-scales over which stats could be accumulated etc.
+// You can also aggregate directly from log data, as is done for testing stats
+func (ss *Sim) TrialStats(accum bool) (sse, avgsse, cosdiff float64) {
+	outLay := ss.Net.LayerByName("ECout").(leabra.LeabraLayer).AsLeabra()
+	ss.TrlCosDiff = float64(outLay.CosDiff.Cos)
+	ss.TrlSSE, ss.TrlAvgSSE = outLay.MSE(0.5) // 0.5 = per-unit tolerance -- right side of .5
+	if accum {
+		ss.SumSSE += ss.TrlSSE
+		ss.SumAvgSSE += ss.TrlAvgSSE
+		ss.SumCosDiff += ss.TrlCosDiff
+		if ss.TrlSSE != 0 {
+			ss.CntErr++
+		}
+	}
+	return
+}
+
+// TrainEpoch runs training trials for remainder of this epoch
+func (ss *Sim) TrainEpoch() {
+	ss.StopNow = false
+	curEpc := ss.TrainEnv.Ctrs().Epoch.Cur
+	curTrial := ss.TrainEnv.Ctrs().Trial.Cur
+	for {
+		ss.TrainTrial()
+		if ss.StopNow || ss.TrainEnv.Ctrs().Epoch.Cur != curEpc || curTrial == ss.TrialperEpc {
+			break
+		}
+	}
+	ss.Stopped()
+}
+
+// TrainRun runs training trials for remainder of run
+func (ss *Sim) TrainRun() {
+	ss.StopNow = false
+	curRun := ss.TrainEnv.Ctrs().Run.Cur
+	for {
+		ss.TrainTrial()
+		if ss.StopNow || ss.TrainEnv.Ctrs().Run.Cur != curRun {
+			break
+		}
+	}
+	ss.Stopped()
+}
+
+// Train runs the full training from this point onward
+func (ss *Sim) Train() {
+	ss.StopNow = false
+	for {
+		ss.TrainTrial()
+		if ss.StopNow {
+			break
+		}
+	}
+	ss.Stopped()
+}
+
+// Stop tells the sim to stop running
+func (ss *Sim) Stop() {
+	ss.StopNow = true
+}
+
+// Stopped is called when a run method stops running -- updates the IsRunning
+// flag and calls the Stopped hook, if any, e.g., to update the toolbar
+func (ss *Sim) Stopped() {
+	ss.IsRunning = false
+	if ss.Hooks.Stopped != nil {
+		ss.Hooks.Stopped()
+	}
+}
+
+// SaveWeights saves the network weights -- when called with giv.CallMethod
+// it will auto-prompt for filename
+func (ss *Sim) SaveWeights(filename gi.FileName) {
+	ss.Net.SaveWtsJSON(filename)
+}
+
+// OpenWeights loads network weights saved by SaveWeights -- when called with
+// giv.CallMethod it will auto-prompt for filename
+func (ss *Sim) OpenWeights(filename gi.FileName) {
+	ss.Net.OpenWtsJSON(filename)
+	ss.UpdateView(true)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////
+// Testing
+
+// TestTrial runs one trial of testing -- always sequentially presented inputs
+func (ss *Sim) TestTrial(returnOnChg bool) {
+	ss.TestEnv.Step()
+
+	// Query counters FIRST
+	_, _, chg := ss.TestEnv.Counter(env.Epoch)
+	if chg {
+		if ss.ViewOn && ss.TestUpdt > leabra.AlphaCycle {
+			ss.UpdateView(false)
+		}
+		if returnOnChg {
+			return
+		}
+	}
+
+	ss.ApplyInputs(&ss.TestEnv)
+	ss.AlphaCyc(false)   // !train
+	ss.TrialStats(false) // !accumulate
+	ss.ProdStats()
+	ss.RecogStats()
+	ss.LogTstTrl(ss.TstTrlLog)
+	if grp := ss.RSAGroup(); grp != "" {
+		ss.RSA.Record(ss.Net, ss.TestEnv.TrialName.Cur, grp)
+	}
+}
+
+// TestItem tests given item which is at given index in test item list
+func (ss *Sim) TestItem(idx int) {
+	cur := ss.TestEnv.Trial.Cur
+	ss.TestEnv.Trial.Cur = idx
+	ss.TestEnv.SetTrialName()
+	ss.ApplyInputs(&ss.TestEnv)
+	ss.AlphaCyc(false)   // !train
+	ss.TrialStats(false) // !accumulate
+	ss.ProdStats()
+	ss.RecogStats()
+	ss.TestEnv.Trial.Cur = cur
+}
+
+// TestAll runs through the full set of testing items, for each of the tables of
+// TestNames
+func (ss *Sim) TestAll() {
+	trn := ss.TrainEnv.Ctrs()
+	ss.RSA.Reset(trn.Run.Cur, trn.Epoch.Prv)
+	ss.NpyRec.Reset(ss.Theta.Test.Cycles())
+	for _, tnm := range ss.TestNames() {
+		ss.TestNm = tnm
+		ss.TestEnv.Table = etable.NewIdxView(ss.TestTable(tnm))
+		ss.TestEnv.Init(ss.TrainEnv.Ctrs().Run.Cur)
+		for {
+			ss.TestTrial(true) // return on chg
+			_, _, chg := ss.TestEnv.Counter(env.Epoch)
+			if chg || ss.StopNow {
+				break
+			}
+		}
+		if ss.StopNow {
+			break
+		}
+	}
+	// log only at very end
+	ss.LogRSA(ss.RSALog)
+	ss.LogTstEpc(ss.TstEpcLog)
+	if ss.NpyRec.On && ss.NpyRec.Save && !ss.StopNow {
+		ss.SaveNpyRec()
+	}
+}
+
+// RSAGroup returns the group of the current test item for the RSA: its pair,
+// community or triad, depending on the Paradigm -- empty if the item is not analyzed
+func (ss *Sim) RSAGroup() string {
+	if ss.TestNm == "Recog" { // pairs of items
+		return ""
+	}
+	switch ss.Paradigm {
+	case Community:
+		return ss.TestEnv.GroupName.Cur
+	case AssocInf:
+		if ss.TestNm == "AC" { // same A cues as the AB test
+			return ""
+		}
+		return ss.TestEnv.GroupName.Cur
+	}
+	return ss.PairEnv.PairName(ss.TestEnv.TrialName.Cur)
+}
+
+// RunTestAll runs through the full set of testing items, has stop running = false at end -- for gui
+func (ss *Sim) RunTestAll() {
+	ss.StopNow = false
+	ss.TestAll()
+	ss.Stopped()
+}
+
+/////////////////////////////////////////////////////////////////////////
+//   Params setting
+
+// ParamsName returns name of current set of parameters
+func (ss *Sim) ParamsName() string {
+	if ss.ParamSet == "" {
+		return "Base"
+	}
+	return ss.ParamSet
+}
+
+// SetParams sets the params for "Base" and then current ParamSet.
+// If sheet is empty, then it applies all avail sheets (e.g., Network, Sim)
+// otherwise just the named sheet
+// if setMsg = true then we output a message for each param that was set.
+func (ss *Sim) SetParams(sheet string, setMsg bool) error {
+	if sheet == "" {
+		// this is important for catching typos and ensuring that all sheets can be used
+		ss.Params.ValidateSheets([]string{"Network", "Sim"})
+	}
+	err := ss.SetParamsSet("Base", sheet, setMsg)
+	if ss.ParamSet != "" && ss.ParamSet != "Base" {
+		err = ss.SetParamsSet(ss.ParamSet, sheet, setMsg)
+	}
+	return err
+}
+
+// SetParamsSet sets the params for given params.Set name.
+// If sheet is empty, then it applies all avail sheets (e.g., Network, Sim)
+// otherwise just the named sheet
+// if setMsg = true then we output a message for each param that was set.
+func (ss *Sim) SetParamsSet(setNm string, sheet string, setMsg bool) error {
+	pset, err := ss.Params.SetByNameTry(setNm)
+	if err != nil {
+		return err
+	}
+	if sheet == "" || sheet == "Network" {
+		netp, ok := pset.Sheets["Network"]
+		if ok {
+			ss.Net.ApplyParams(netp, setMsg)
+		}
+	}
+
+	if sheet == "" || sheet == "Sim" {
+		simp, ok := pset.Sheets["Sim"]
+		if ok {
+			simp.Apply(ss, setMsg)
+		}
+	}
+	// note: if you have more complex environments with parameters, definitely add
+	// sheets for them, e.g., "TrainEnv", "TestEnv" etc
+	return err
+}
+
+func (ss *Sim) OpenPat(dt *etable.Table, fname, name, desc string) {
+	err := dt.OpenCSV(gi.FileName(fname), etable.Tab)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if CppLayout(dt) {
+		fmt.Printf("OpenPat: %s has the legacy C++ layout of tensor columns -- reshaping for Go\n", fname)
+		patgen.ReshapeCpp(dt)
+	}
+	dt.SetMetaData("name", name)
+	dt.SetMetaData("desc", desc)
+	for _, pf := range ss.PatFiles {
+		if pf == fname {
+			return
+		}
+	}
+	ss.PatFiles = append(ss.PatFiles, fname)
+}
+
+// not using this is the only diff from sims version:
+// // OpenPatAsset opens pattern file from embedded assets
+// func (ss *Sim) OpenPatAsset(dt *etable.Table, fnm, name, desc string) error {
+// 	dt.SetMetaData("name", name)
+// 	dt.SetMetaData("desc", desc)
+// 	ab, err := Asset(fnm)
+// 	if err != nil {
+// 		log.Println(err)
+// 		return err
+// 	}
+// 	err = dt.ReadCSV(bytes.NewBuffer(ab), etable.Tab)
+// 	if err != nil {
+// 		log.Println(err)
+// 	} else {
+// 		for i := 1; i < len(dt.Cols); i++ {
+// 			dt.Cols[i].SetMetaData("grid-fill", "0.9")
+// 		}
+// 	}
+// 	return err
+// }
+
+func (ss *Sim) OpenPats() {
+	// one-time conversion from C++ patterns to Go patterns, e.g., Train_pairs.dat:
+	// hip-sl convert Train_pairs.dat -- see Convert
+
+	ss.OpenPat(ss.TestAB, "Test_pairs_go.dat", "AB Testing Patterns", "AB Testing Patterns")
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////
+// 		Logging
+
+// RunName returns a name for this run that combines Tag and Params -- add this to
+// any file names that are saved.
+func (ss *Sim) RunName() string {
+	if ss.Tag != "" {
+		pnm := ss.ParamsName()
+		if pnm == "Base" {
+			return ss.Tag
+		} else {
+			return ss.Tag + "_" + pnm
+		}
+	} else {
+		return ss.ParamsName()
+	}
+}
+
+// RunEpochName returns a string with the run and epoch numbers with leading zeros, suitable
+// for using in weights file names.  Uses 3, 5 digits for each.
+func (ss *Sim) RunEpochName(run, epc int) string {
+	return fmt.Sprintf("%03d_%05d", run, epc)
+}
+
+// WeightsFileName returns default current weights file name
+func (ss *Sim) WeightsFileName() string {
+	return ss.Net.Nm + "_" + ss.RunName() + "_" + ss.RunEpochName(ss.TrainEnv.Ctrs().Run.Cur, ss.TrainEnv.Ctrs().Epoch.Cur) + ".wts"
+}
+
+// LogFileName returns default log file name
+func (ss *Sim) LogFileName(lognm string) string {
+	return ss.Net.Nm + "_" + ss.RunName() + "_" + lognm + ".csv"
+}
+
+// ArrowFileName returns default name of the Arrow IPC file of a log
+func (ss *Sim) ArrowFileName(lognm string) string {
+	return ss.Net.Nm + "_" + ss.RunName() + "_" + lognm + ".arrow"
+}
+
+//////////////////////////////////////////////
+//  TrnTrlLog
+
+// LogTrnTrl adds data from current trial to the TrnTrlLog table.
+// log always contains number of testing items
+func (ss *Sim) LogTrnTrl(dt *etable.Table) {
+	epc := ss.TrainEnv.Ctrs().Epoch.Cur
+	trl := ss.TrainEnv.Ctrs().Trial.Cur
+
+	row := dt.Rows
+	if trl == 0 { // reset at start
+		row = 0
+	}
+	dt.SetNumRows(row + 1)
+
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Ctrs().Run.Cur))
+	dt.SetCellFloat("Epoch", row, float64(epc))
+	dt.SetCellFloat("Trial", row, float64(trl))
+	dt.SetCellString("TrialName", row, ss.TrainEnv.Ctrs().TrialName.Cur)
+	dt.SetCellFloat("SSE", row, ss.TrlSSE)
+	dt.SetCellFloat("AvgSSE", row, ss.TrlAvgSSE)
+	dt.SetCellFloat("CosDiff", row, ss.TrlCosDiff)
+
+	dt.SetCellFloat("Mem", row, ss.Mem)
+	dt.SetCellFloat("TrgOnWasOff", row, ss.TrgOnWasOffAll)
+	dt.SetCellFloat("TrgOffWasOn", row, ss.TrgOffWasOn)
+
+	ss.LogUpdated(dt, row)
+}
+
+func (ss *Sim) ConfigTrnTrlLog(dt *etable.Table) {
+	// inLay := ss.Net.LayerByName("Input").(leabra.LeabraLayer).AsLeabra()
+	// outLay := ss.Net.LayerByName("Output").(leabra.LeabraLayer).AsLeabra()
+
+	dt.SetMetaData("name", "TrnTrlLog")
+	dt.SetMetaData("desc", "Record of training per input pattern")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	nt := ss.TestEnv.Table.Len() // number in view
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"Trial", etensor.INT64, nil, nil},
+		{"TrialName", etensor.STRING, nil, nil},
+		{"SSE", etensor.FLOAT64, nil, nil},
+		{"AvgSSE", etensor.FLOAT64, nil, nil},
+		{"CosDiff", etensor.FLOAT64, nil, nil},
+		{"Mem", etensor.FLOAT64, nil, nil},
+		{"TrgOnWasOff", etensor.FLOAT64, nil, nil},
+		{"TrgOffWasOn", etensor.FLOAT64, nil, nil},
+	}
+	dt.SetFromSchema(sch, nt)
+}
+
+//////////////////////////////////////////////
+//  TrnEpcLog
+
+// LogTrnEpc adds data from current epoch to the TrnEpcLog table.
+// computes epoch averages prior to logging.
+func (ss *Sim) LogTrnEpc(dt *etable.Table) {
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	epc := ss.TrainEnv.Ctrs().Epoch.Prv         // this is triggered by increment so use previous value
+	nt := float64(ss.TrainEnv.Ctrs().Trial.Max) // number of trials per epoch
+
+	ss.EpcSSE = ss.SumSSE / nt
+	ss.SumSSE = 0
+	ss.EpcAvgSSE = ss.SumAvgSSE / nt
+	ss.SumAvgSSE = 0
+	ss.EpcPctErr = float64(ss.CntErr) / nt
+	ss.CntErr = 0
+	ss.EpcPctCor = 1 - ss.EpcPctErr
+	ss.EpcCosDiff = ss.SumCosDiff / nt
+	ss.SumCosDiff = 0
+
+	trlog := ss.TrnTrlLog
+	tix := etable.NewIdxView(trlog)
+
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Ctrs().Run.Cur))
+	dt.SetCellFloat("Epoch", row, float64(epc))
+	dt.SetCellFloat("SSE", row, ss.EpcSSE)
+	dt.SetCellFloat("AvgSSE", row, ss.EpcAvgSSE)
+	dt.SetCellFloat("PctErr", row, ss.EpcPctErr)
+	dt.SetCellFloat("PctCor", row, ss.EpcPctCor)
+	dt.SetCellFloat("CosDiff", row, ss.EpcCosDiff)
+
+	mem := agg.Mean(tix, "Mem")[0]
+	dt.SetCellFloat("Mem", row, mem)
+	dt.SetCellFloat("TrgOnWasOff", row, agg.Mean(tix, "TrgOnWasOff")[0])
+	dt.SetCellFloat("TrgOffWasOn", row, agg.Mean(tix, "TrgOffWasOn")[0])
+
+	for _, lnm := range ss.LayStatNms {
+		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
+		dt.SetCellFloat(ly.Nm+" ActAvg", row, float64(ly.Pools[0].ActAvg.ActPAvgEff))
+	}
+	dt.SetCellString("Manifest", row, ss.ManifestRef())
+
+	ss.LogUpdated(dt, row)
+}
+
+func (ss *Sim) ConfigTrnEpcLog(dt *etable.Table) {
+	dt.SetMetaData("name", "TrnEpcLog")
+	dt.SetMetaData("desc", "Record of performance over epochs of training")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"SSE", etensor.FLOAT64, nil, nil},
+		{"AvgSSE", etensor.FLOAT64, nil, nil},
+		{"PctErr", etensor.FLOAT64, nil, nil},
+		{"PctCor", etensor.FLOAT64, nil, nil},
+		{"CosDiff", etensor.FLOAT64, nil, nil},
+		{"Mem", etensor.FLOAT64, nil, nil},
+		{"TrgOnWasOff", etensor.FLOAT64, nil, nil},
+		{"TrgOffWasOn", etensor.FLOAT64, nil, nil},
+	}
+	for _, lnm := range ss.LayStatNms {
+		sch = append(sch, etable.Column{lnm + " ActAvg", etensor.FLOAT64, nil, nil})
+	}
+	sch = append(sch, etable.Column{"Manifest", etensor.STRING, nil, nil})
+	dt.SetFromSchema(sch, 0)
+}
+
+//////////////////////////////////////////////
+//  TstTrlLog
+
+// LogTstTrl adds data from current trial to the TstTrlLog table.
+// log always contains number of testing items
+func (ss *Sim) LogTstTrl(dt *etable.Table) {
+	epc := ss.TrainEnv.Ctrs().Epoch.Prv // this is triggered by increment so use previous value
+	trl := ss.TestEnv.Trial.Cur
+
+	row := dt.Rows
+	if ss.TestNm == ss.TstNms[0] && trl == 0 { // reset at start
+		row = 0
+	}
+	dt.SetNumRows(row + 1)
+
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Ctrs().Run.Cur))
+	dt.SetCellFloat("Epoch", row, float64(epc))
+	dt.SetCellString("TestNm", row, ss.TestNm)
+	dt.SetCellFloat("Trial", row, float64(row))
+	dt.SetCellString("TrialName", row, ss.TestEnv.TrialName.Cur)
+	dt.SetCellString("Group", row, ss.TestEnv.GroupName.Cur)
+	dt.SetCellFloat("SSE", row, ss.TrlSSE)
+	dt.SetCellFloat("AvgSSE", row, ss.TrlAvgSSE)
+	dt.SetCellFloat("CosDiff", row, ss.TrlCosDiff)
+
+	dt.SetCellFloat("Mem", row, ss.Mem)
+	dt.SetCellFloat("TrgOnWasOff", row, ss.TrgOnWasOffCmp)
+	dt.SetCellFloat("TrgOffWasOn", row, ss.TrgOffWasOn)
+	dt.SetCellFloat("ProdSelf", row, ss.ProdSelf)
+	dt.SetCellFloat("ProdPartner", row, ss.ProdPartner)
+	dt.SetCellFloat("ProdOther", row, ss.ProdOther)
+	dt.SetCellTensor("ProdProbs", row, &ss.ProdProbs)
+	dt.SetCellFloat("Match", row, ss.Match)
+
+	for _, lnm := range ss.LayStatNms {
+		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
+		dt.SetCellFloat(ly.Nm+" ActM.Avg", row, float64(ly.Pools[0].ActM.Avg))
+		tsr := ss.ValsTsr(lnm)
+		ly.UnitValsTensor(tsr, "ActM")
+		dt.SetCellTensor(lnm+" ActM", row, tsr)
+	}
+	dt.SetCellString("Manifest", row, ss.ManifestRef())
+
+	ss.LogUpdated(dt, row)
+}
+
+func (ss *Sim) ConfigTstTrlLog(dt *etable.Table) {
+	// inLay := ss.Net.LayerByName("Input").(leabra.LeabraLayer).AsLeabra()
+	// outLay := ss.Net.LayerByName("Output").(leabra.LeabraLayer).AsLeabra()
+
+	dt.SetMetaData("name", "TstTrlLog")
+	dt.SetMetaData("desc", "Record of testing per input pattern")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	nt := ss.NTestTrials()
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"TestNm", etensor.STRING, nil, nil},
+		{"Trial", etensor.INT64, nil, nil},
+		{"TrialName", etensor.STRING, nil, nil},
+		{"Group", etensor.STRING, nil, nil},
+		{"SSE", etensor.FLOAT64, nil, nil},
+		{"AvgSSE", etensor.FLOAT64, nil, nil},
+		{"CosDiff", etensor.FLOAT64, nil, nil},
+		{"Mem", etensor.FLOAT64, nil, nil},
+		{"TrgOnWasOff", etensor.FLOAT64, nil, nil},
+		{"TrgOffWasOn", etensor.FLOAT64, nil, nil},
+		{"ProdSelf", etensor.FLOAT64, nil, nil},
+		{"ProdPartner", etensor.FLOAT64, nil, nil},
+		{"ProdOther", etensor.FLOAT64, nil, nil},
+		{"ProdProbs", etensor.FLOAT32, []int{len(ss.TrainEnv.ItemNames())}, nil},
+		{"Match", etensor.FLOAT64, nil, nil},
+	}
+	for _, lnm := range ss.LayStatNms {
+		sch = append(sch, etable.Column{lnm + " ActM.Avg", etensor.FLOAT64, nil, nil})
+	}
+	for _, lnm := range ss.LayStatNms {
+		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
+		sch = append(sch, etable.Column{lnm + " ActM", etensor.FLOAT32, ly.Shp.Shp, nil})
+	}
+	sch = append(sch, etable.Column{"Manifest", etensor.STRING, nil, nil})
+	// sch = append(sch, etable.Schema{
+	// 	{"InAct", etensor.FLOAT64, inLay.Shp.Shp, nil},
+	// 	{"OutActM", etensor.FLOAT64, outLay.Shp.Shp, nil},
+	// 	{"OutActP", etensor.FLOAT64, outLay.Shp.Shp, nil},
+	// }...)
+	dt.SetFromSchema(sch, nt)
+}
+
+//////////////////////////////////////////////
+//  TstEpcLog
+
+func (ss *Sim) LogTstEpc(dt *etable.Table) {
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	trl := ss.TstTrlLog
+	tix := etable.NewIdxView(trl)
+	epc := ss.TrainEnv.Ctrs().Epoch.Prv // ?
+
+	if ss.LastEpcTime.IsZero() {
+		ss.EpcPerTrlMSec = 0
+	} else {
+		iv := time.Now().Sub(ss.LastEpcTime)
+		nt := ss.TrainEnv.Ctrs().Trial.Max + ss.NTestTrials() // train and test trials
+		ss.EpcPerTrlMSec = float64(iv) / (float64(nt) * float64(time.Millisecond))
+	}
+	ss.LastEpcTime = time.Now()
+
+	// note: this shows how to use agg methods to compute summary data from another
+	// data table, instead of incrementing on the Sim
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Ctrs().Run.Cur))
+	dt.SetCellFloat("Epoch", row, float64(epc))
+	dt.SetCellFloat("PerTrlMSec", row, ss.EpcPerTrlMSec)
+	dt.SetCellFloat("SSE", row, agg.Sum(tix, "SSE")[0])
+	dt.SetCellFloat("AvgSSE", row, agg.Mean(tix, "AvgSSE")[0])
+	dt.SetCellFloat("PctErr", row, agg.PropIf(tix, "SSE", func(idx int, val float64) bool {
+		return val > 0
+	})[0])
+	dt.SetCellFloat("PctCor", row, agg.PropIf(tix, "SSE", func(idx int, val float64) bool {
+		return val == 0
+	})[0])
+	dt.SetCellFloat("CosDiff", row, agg.Mean(tix, "CosDiff")[0])
+
+	trix := etable.NewIdxView(trl)
+	spl := split.GroupBy(trix, []string{"TestNm"})
+	for _, ts := range ss.TstStatNms {
+		split.Agg(spl, ts, agg.AggMean)
+	}
+	ss.TstStats = spl.AggsToTable(etable.ColNameOnly)
+	for ri, six := range spl.Splits { // not scored in any trial, e.g., Mem of the Community test
+		for _, ts := range ss.TstStatNms {
+			if agg.Count(six, ts)[0] == 0 {
+				ss.TstStats.SetCellFloat(ts, ri, math.NaN())
+			}
+		}
+	}
+
+	for ri := 0; ri < ss.TstStats.Rows; ri++ {
+		tst := ss.TstStats.CellString("TestNm", ri)
+		for _, ts := range ss.TstStatNms {
+			dt.SetCellFloat(tst+" "+ts, row, ss.TstStats.CellFloat(ts, ri))
+		}
+	}
+
+	if ss.Paradigm == Community {
+		for _, lnm := range ss.LayStatNms {
+			within, between := ss.RSA.Sims(lnm)
+			dt.SetCellFloat(lnm+" WithinComm", row, within)
+			dt.SetCellFloat(lnm+" BetweenComm", row, between)
+		}
+	}
+	if ss.Paradigm == AssocInf {
+		dt.SetCellFloat("Infer", row, ss.InferStat(trl))
+	}
+	if ss.RecogOn {
+		ss.LogRecog(dt, row, trl)
+	}
+	dt.SetCellString("Manifest", row, ss.ManifestRef())
+
+	// base zero on testing performance! -- NaN Mem, if the test is not scored, never is
+	mem := dt.CellFloat(ss.TstNms[0]+" Mem", row)
+	if math.IsNaN(mem) && ss.NZeroStop > 0 && !ss.NZeroWarned {
+		log.Printf("warning: NZeroStop is %d but the %s test has no Mem score (its items have nothing to complete), so training runs all MaxEpcs epochs\n", ss.NZeroStop, ss.TstNms[0])
+		ss.NZeroWarned = true
+	}
+	if ss.FirstZero < 0 && mem == 1 {
+		ss.FirstZero = epc
+	}
+	if mem == 1 {
+		ss.NZero++
+	} else {
+		ss.NZero = 0
+	}
+
+	ss.LogUpdated(dt, row)
+}
+
+func (ss *Sim) ConfigTstEpcLog(dt *etable.Table) {
+	dt.SetMetaData("name", "TstEpcLog")
+	dt.SetMetaData("desc", "Summary stats for testing trials")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"PerTrlMSec", etensor.FLOAT64, nil, nil},
+		{"SSE", etensor.FLOAT64, nil, nil},
+		{"AvgSSE", etensor.FLOAT64, nil, nil},
+		{"PctErr", etensor.FLOAT64, nil, nil},
+		{"PctCor", etensor.FLOAT64, nil, nil},
+		{"CosDiff", etensor.FLOAT64, nil, nil},
+	}
+	for _, tn := range ss.TstNms {
+		for _, ts := range ss.TstStatNms {
+			sch = append(sch, etable.Column{tn + " " + ts, etensor.FLOAT64, nil, nil})
+		}
+	}
+	if ss.Paradigm == Community {
+		for _, lnm := range ss.LayStatNms {
+			sch = append(sch, etable.Column{lnm + " WithinComm", etensor.FLOAT64, nil, nil})
+			sch = append(sch, etable.Column{lnm + " BetweenComm", etensor.FLOAT64, nil, nil})
+		}
+	}
+	if ss.Paradigm == AssocInf {
+		sch = append(sch, etable.Column{"Infer", etensor.FLOAT64, nil, nil})
+	}
+	if ss.RecogOn {
+		for _, cl := range RecogCols() {
+			sch = append(sch, etable.Column{cl, etensor.FLOAT64, nil, nil})
+		}
+	}
+	sch = append(sch, etable.Column{"Manifest", etensor.STRING, nil, nil})
+	dt.SetFromSchema(sch, 0)
+}
+
+// InferStat returns the proportion of triads in the TstTrlLog whose transitive AC
+// test met the memory criterion, out of those whose AB and BC pairs both did --
+// triads are identified by the Group column.  Returns NaN if no triad has both pairs,
+// as there is nothing to infer from.
+func (ss *Sim) InferStat(dt *etable.Table) float64 {
+	pairs := make(map[string]int)
+	infer := make(map[string]bool)
+	for i := 0; i < dt.Rows; i++ {
+		if dt.CellFloat("Mem", i) != 1 {
+			continue
+		}
+		triad := dt.CellString("Group", i)
+		switch dt.CellString("TestNm", i) {
+		case "AB", "BC":
+			pairs[triad]++
+		case "AC":
+			infer[triad] = true
+		}
+	}
+	nboth, ninf := 0, 0
+	for triad, np := range pairs {
+		if np < 2 {
+			continue
+		}
+		nboth++
+		if infer[triad] {
+			ninf++
+		}
+	}
+	if nboth == 0 {
+		return math.NaN()
+	}
+	return float64(ninf) / float64(nboth)
+}
+
+//////////////////////////////////////////////
+//  RSALog
+
+// LogRSA computes the RSA of the current test and adds the within vs. across
+// group similarity of each layer to the RSALog table
+func (ss *Sim) LogRSA(dt *etable.Table) {
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	epc := ss.TrainEnv.Ctrs().Epoch.Prv
+	ss.RSA.Compute()
+
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Ctrs().Run.Cur))
+	dt.SetCellFloat("Epoch", row, float64(epc))
+	for _, lnm := range ss.RSA.Layers {
+		within, across := ss.RSA.Sims(lnm)
+		dt.SetCellFloat(lnm+" Within", row, within)
+		dt.SetCellFloat(lnm+" Across", row, across)
+	}
+	dt.SetCellString("Manifest", row, ss.ManifestRef())
+
+	ss.LogUpdated(dt, row)
+}
+
+func (ss *Sim) ConfigRSALog(dt *etable.Table) {
+	dt.SetMetaData("name", "RSALog")
+	dt.SetMetaData("desc", "Representational similarity of test items within vs. across groups")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+	}
+	for _, lnm := range ss.RSA.Layers {
+		sch = append(sch, etable.Column{lnm + " Within", etensor.FLOAT64, nil, nil})
+		sch = append(sch, etable.Column{lnm + " Across", etensor.FLOAT64, nil, nil})
+	}
+	sch = append(sch, etable.Column{"Manifest", etensor.STRING, nil, nil})
+	dt.SetFromSchema(sch, 0)
+}
+
+//////////////////////////////////////////////
+//  TstCycLog
+
+// ValsTsr gets value tensor of given name, creating if not yet made
+func (ss *Sim) ValsTsr(name string) *etensor.Float32 {
+	if ss.ValsTsrs == nil {
+		ss.ValsTsrs = make(map[string]*etensor.Float32)
+	}
+	tsr, ok := ss.ValsTsrs[name]
+	if !ok {
+		tsr = &etensor.Float32{}
+		ss.ValsTsrs[name] = tsr
+	}
+	return tsr
+}
+
+// LogTstCyc adds data from current trial to the TstCycLog table.
+// log just has 100 cycles, is overwritten
+func (ss *Sim) LogTstCyc(dt *etable.Table, cyc int) {
+	if dt.Rows <= cyc {
+		dt.SetNumRows(cyc + 1)
+	}
+
+	dt.SetCellFloat("Cycle", cyc, float64(cyc))
+	for _, lnm := range ss.LayStatNms {
+		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
+		dt.SetCellFloat(ly.Nm+" Ge.Avg", cyc, float64(ly.Pools[0].Inhib.Ge.Avg))
+		dt.SetCellFloat(ly.Nm+" Act.Avg", cyc, float64(ly.Pools[0].Inhib.Act.Avg))
+	}
+
+	ss.LogUpdated(dt, cyc)
+}
+
+func (ss *Sim) ConfigTstCycLog(dt *etable.Table) {
+	dt.SetMetaData("name", "TstCycLog")
+	dt.SetMetaData("desc", "Record of activity etc over one trial by cycle")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	np := 100 // max cycles
+	sch := etable.Schema{
+		{"Cycle", etensor.INT64, nil, nil},
+	}
+	for _, lnm := range ss.LayStatNms {
+		sch = append(sch, etable.Column{lnm + " Ge.Avg", etensor.FLOAT64, nil, nil})
+		sch = append(sch, etable.Column{lnm + " Act.Avg", etensor.FLOAT64, nil, nil})
+	}
+	dt.SetFromSchema(sch, np)
+}
+
+//////////////////////////////////////////////
+//  RunLog
+
+// RunLogParams returns the Params of the RunLog: the RunName, including the tag,
+// and the Lesions, if any
+func (ss *Sim) RunLogParams() string {
+	params := ss.RunName()
+	if len(ss.Lesions) > 0 {
+		params += "_" + ss.Lesions.String()
+	}
+	return params
+}
+
+// LogRun adds data from current run to the RunLog table.
+func (ss *Sim) LogRun(dt *etable.Table) {
+	run := ss.TrainEnv.Ctrs().Run.Cur // this is NOT triggered by increment yet -- use Cur
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	epclog := ss.TstEpcLog
+	epcix := etable.NewIdxView(epclog)
+	// compute mean over last N epochs for run level
+	nlast := 1
+	if nlast > epcix.Len()-1 {
+		nlast = epcix.Len() - 1
+	}
+	epcix.Idxs = epcix.Idxs[epcix.Len()-nlast:]
+
+	params := ss.RunLogParams()
+
+	fzero := float64(ss.FirstZero)
+	if fzero < 0 {
+		fzero = float64(ss.MaxEpcs)
+		if agg.Count(etable.NewIdxView(epclog), ss.TstNms[0]+" Mem")[0] == 0 { // Mem not scored
+			fzero = math.NaN()
+		}
+	}
+
+	dt.SetCellFloat("Run", row, float64(run))
+	dt.SetCellString("Params", row, params)
+	// seeds are strings so they are saved exactly, regardless of LogPrec
+	dt.SetCellString("Seed", row, strconv.FormatInt(ss.RndSeed, 10))
+	dt.SetCellString("RunSeed", row, strconv.FormatInt(ss.Seeds.Run, 10))
+	dt.SetCellString("ConnSeed", row, strconv.FormatInt(ss.Seeds.Conn, 10))
+	dt.SetCellString("WtsSeed", row, strconv.FormatInt(ss.Seeds.Wts, 10))
+	dt.SetCellString("EnvSeed", row, strconv.FormatInt(ss.Seeds.Env, 10))
+	dt.SetCellFloat("NEpochs", row, float64(ss.TstEpcLog.Rows))
+	dt.SetCellFloat("FirstZero", row, fzero)
+	dt.SetCellFloat("SSE", row, agg.Mean(epcix, "SSE")[0])
+	dt.SetCellFloat("AvgSSE", row, agg.Mean(epcix, "AvgSSE")[0])
+	dt.SetCellFloat("PctErr", row, agg.Mean(epcix, "PctErr")[0])
+	dt.SetCellFloat("PctCor", row, agg.Mean(epcix, "PctCor")[0])
+	dt.SetCellFloat("CosDiff", row, agg.Mean(epcix, "CosDiff")[0])
+
+	for _, tn := range ss.TstNms {
+		for _, ts := range ss.TstStatNms {
+			nm := tn + " " + ts
+			if agg.Count(epcix, nm)[0] == 0 {
+				dt.SetCellFloat(nm, row, math.NaN())
+			} else {
+				dt.SetCellFloat(nm, row, agg.Mean(epcix, nm)[0])
+			}
+		}
+	}
+	if ss.Paradigm == AssocInf {
+		if agg.Count(epcix, "Infer")[0] == 0 { // no triad had both pairs
+			dt.SetCellFloat("Infer", row, math.NaN())
+		} else {
+			dt.SetCellFloat("Infer", row, agg.Mean(epcix, "Infer")[0])
+		}
+	}
+	if ss.RecogOn {
+		for _, cl := range RecogCols() {
+			if agg.Count(epcix, cl)[0] == 0 { // no lures of the kind
+				dt.SetCellFloat(cl, row, math.NaN())
+			} else {
+				dt.SetCellFloat(cl, row, agg.Mean(epcix, cl)[0])
+			}
+		}
+	}
+	dt.SetCellString("Manifest", row, ss.ManifestRef())
+
+	ss.LogRunStats(dt)
+
+	ss.LogUpdated(dt, row)
+}
+
+// LogRunStats computes the RunStats from given RunLog: the Mem and FirstZero
+// stats of the runs of each Params
+func (ss *Sim) LogRunStats(dt *etable.Table) {
+	runix := etable.NewIdxView(dt)
+	spl := split.GroupBy(runix, []string{"Params"})
+	for _, tn := range ss.TstNms {
+		nm := tn + " " + "Mem"
+		split.Desc(spl, nm)
+	}
+	split.Desc(spl, "FirstZero")
+	ss.RunStats = spl.AggsToTable(etable.AddAggName)
+}
+
+func (ss *Sim) ConfigRunLog(dt *etable.Table) {
+	dt.SetMetaData("name", "RunLog")
+	dt.SetMetaData("desc", "Record of performance at end of training")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Params", etensor.STRING, nil, nil},
+		{"Seed", etensor.STRING, nil, nil},
+		{"RunSeed", etensor.STRING, nil, nil},
+		{"ConnSeed", etensor.STRING, nil, nil},
+		{"WtsSeed", etensor.STRING, nil, nil},
+		{"EnvSeed", etensor.STRING, nil, nil},
+		{"NEpochs", etensor.FLOAT64, nil, nil},
+		{"FirstZero", etensor.FLOAT64, nil, nil},
+		{"SSE", etensor.FLOAT64, nil, nil},
+		{"AvgSSE", etensor.FLOAT64, nil, nil},
+		{"PctErr", etensor.FLOAT64, nil, nil},
+		{"PctCor", etensor.FLOAT64, nil, nil},
+		{"CosDiff", etensor.FLOAT64, nil, nil},
+	}
+	for _, tn := range ss.TstNms {
+		for _, ts := range ss.TstStatNms {
+			sch = append(sch, etable.Column{tn + " " + ts, etensor.FLOAT64, nil, nil})
+		}
+	}
+	if ss.Paradigm == AssocInf {
+		sch = append(sch, etable.Column{"Infer", etensor.FLOAT64, nil, nil})
+	}
+	if ss.RecogOn {
+		for _, cl := range RecogCols() {
+			sch = append(sch, etable.Column{cl, etensor.FLOAT64, nil, nil})
+		}
+	}
+	sch = append(sch, etable.Column{"Manifest", etensor.STRING, nil, nil})
+	dt.SetFromSchema(sch, 0)
+}
+
+// These props register Save methods so they can be used
+var SimProps = ki.Props{
+	"CallMethods": ki.PropSlice{
+		{"SaveWeights", ki.Props{
+			"desc": "save network weights to file",
+			"icon": "file-save",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".wts,.wts.gz",
+				}},
+			},
+		}},
+		{"OpenWeights", ki.Props{
+			"desc": "open network weights from file",
+			"icon": "file-open",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".wts,.wts.gz",
+				}},
+			},
+		}},
+		{"Resume", ki.Props{
+			"desc": "resume training from a checkpoint directory, restoring its paradigm, params, weights, counters, random state and logs",
+			"icon": "file-open",
+			"Args": ki.PropSlice{
+				{"Checkpoint Dir", ki.Props{}},
+			},
+		}},
+		{"OpenTopology", ki.Props{
+			"desc": "open the topology of the network (layers and projections) from a JSON or TOML file -- reconfigures the network and logs",
+			"icon": "file-open",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".json,.toml",
+				}},
+			},
+		}},
+		{"SaveTopology", ki.Props{
+			"desc": "save the topology of the network (layers and projections) to a JSON or TOML file",
+			"icon": "file-save",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".json,.toml",
+				}},
+			},
+		}},
+		{"OpenParams", ki.Props{
+			"desc": "open param sets from a JSON or TOML file and merge them into the Params: a Base set overrides the params of Base, a set named as an existing one overrides its params, and the others are added, to select as ParamSet",
+			"icon": "file-open",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".json,.toml",
+				}},
+			},
+		}},
+		{"SaveParams", ki.Props{
+			"desc": "save the params currently applied (Base and ParamSet) as a single Base set, to a JSON or TOML file, or as Go code to replace params.go with a .go file",
+			"icon": "file-save",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".json,.toml,.go",
+				}},
+			},
+		}},
+		{"DiffParams", ki.Props{
+			"desc": "write the differences between two sources of params, of the params of their selectors and of the effective values of each layer and projection, to a CSV or markdown file -- a source is compiled, a JSON or TOML file of param sets (e.g., the hip.params of the leabra hip example), or defaults, optionally followed by :Set to apply on top of Base",
+			"icon": "file-save",
+			"Args": ki.PropSlice{
+				{"Source A", ki.Props{}},
+				{"Source B", ki.Props{}},
+				{"File Name", ki.Props{
+					"ext": ".csv,.md",
+				}},
+			},
+		}},
+		{"SetLesions", ki.Props{
+			"desc": "set the lesions as a comma-separated list of Type:Name@When -- Type is SilenceLayer (Name is a layer), ZeroWtScale or FreezeLrn (Name is a projection, e.g., CA3ToCA1), When is Train, Test or Both -- or a preset: MSPonly or TSPonly -- empty for none",
+			"icon": "gear",
+			"Args": ki.PropSlice{
+				{"Lesions", ki.Props{}},
+			},
+		}},
+		{"SetParadigm", ki.Props{
+			"desc": "select the statistical learning paradigm to train and test on -- reconfigures the network and logs",
+			"icon": "gear",
+			"Args": ki.PropSlice{
+				{"Paradigm", ki.Props{
+					"default-field": "Paradigm",
+				}},
+			},
+		}},
+		{"SetRecog", ki.Props{
+			"desc": "turn the recognition test of studied pairs vs. lures on or off -- reconfigures the logs",
+			"icon": "gear",
+			"Args": ki.PropSlice{
+				{"On", ki.Props{
+					"default-field": "RecogOn",
+				}},
+			},
+		}},
+	},
+}
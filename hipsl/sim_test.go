@@ -0,0 +1,66 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"math"
+	"testing"
+
+	"github.com/emer/leabra/leabra"
+)
+
+func TestProdStats(t *testing.T) {
+	chdirRoot(t)
+	ss, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecout := ss.Net.LayerByName("ECout").(leabra.LeabraLayer).AsLeabra()
+	acts := []float32{0.6, 0.2, 0, 0.1, 0, 0, 0.1, 0, 1, 1, 1, 1} // items sum to 1, last 4 units are not items
+	for i := range ecout.Neurons {
+		ecout.Neurons[i].ActM = acts[i]
+	}
+	ss.TestEnv.TrialName.Cur = "A"
+	ss.ProdStats()
+	ni := len(ss.PairEnv.Items)
+	if ss.ProdProbs.Len() != ni {
+		t.Fatalf("%d ProdProbs, want one per item: %d", ss.ProdProbs.Len(), ni)
+	}
+	sum := float32(0)
+	for i, p := range ss.ProdProbs.Values {
+		sum += p
+		if want := acts[i]; math.Abs(float64(p-want)) > 1e-6 {
+			t.Errorf("ProdProbs[%s] = %g, want %g", ss.PairEnv.Items[i], p, want)
+		}
+	}
+	if math.Abs(float64(sum-1)) > 1e-6 {
+		t.Errorf("ProdProbs sum to %g, want 1", sum)
+	}
+	if math.Abs(ss.ProdSelf-0.6) > 1e-6 || math.Abs(ss.ProdPartner-0.2) > 1e-6 || math.Abs(ss.ProdOther-0.2) > 1e-6 {
+		t.Errorf("ProdSelf, Partner, Other = %g, %g, %g, want 0.6, 0.2, 0.2", ss.ProdSelf, ss.ProdPartner, ss.ProdOther)
+	}
+
+	for i := range ecout.Neurons { // scaled up, same probabilities
+		ecout.Neurons[i].ActM = 0.5 * acts[i]
+	}
+	ss.TestEnv.TrialName.Cur = "B"
+	ss.ProdStats()
+	if math.Abs(ss.ProdSelf-0.2) > 1e-6 || math.Abs(ss.ProdPartner-0.6) > 1e-6 {
+		t.Errorf("cue B: ProdSelf, Partner = %g, %g, want 0.2, 0.6", ss.ProdSelf, ss.ProdPartner)
+	}
+
+	for i := range ecout.Neurons {
+		ecout.Neurons[i].ActM = 0
+	}
+	ss.ProdStats()
+	if !math.IsNaN(ss.ProdSelf) || !math.IsNaN(ss.ProdPartner) || !math.IsNaN(ss.ProdOther) {
+		t.Errorf("nothing produced: ProdSelf, Partner, Other = %g, %g, %g, want NaN", ss.ProdSelf, ss.ProdPartner, ss.ProdOther)
+	}
+	for i, p := range ss.ProdProbs.Values {
+		if p != 0 {
+			t.Errorf("nothing produced: ProdProbs[%d] = %g, want 0", i, p)
+		}
+	}
+}
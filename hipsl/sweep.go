@@ -0,0 +1,247 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/emer/emergent/params"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// SweepDim is one dimension of a parameter sweep: a param of the network, or of
+// the Sim, and the values to sweep it over
+type SweepDim struct {
+	Sel  string   `desc:"selector of the param in its sheet, e.g., #CA3ToCA3 or .HippoCHL -- Sim for a param of the Sim sheet"`
+	Path string   `desc:"path of the param, e.g., Prjn.Learn.Lrate or Sim.TrialperEpc"`
+	Vals []string `desc:"values to sweep over"`
+}
+
+// Name returns the name of the dimension as Sel:Path, e.g., #CA3ToCA3:Prjn.Learn.Lrate
+func (sd *SweepDim) Name() string {
+	return sd.Sel + ":" + sd.Path
+}
+
+// Sheet returns the name of the params sheet of the dimension: Sim or Network
+func (sd *SweepDim) Sheet() string {
+	if sd.Sel == "Sim" {
+		return "Sim"
+	}
+	return "Network"
+}
+
+// IsNumeric returns true if all the values of the dimension are numbers
+func (sd *SweepDim) IsNumeric() bool {
+	for _, v := range sd.Vals {
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Sweep is a grid search over the cartesian product of the values of its
+// dimensions, each point of which is run as a params.Set derived from the ParamSet
+type Sweep []SweepDim
+
+// ParseSweep returns the sweep from a spec of dimensions separated by ;, each
+// Sel:Path=Vals, where Vals is a comma-separated list of values, or a range
+// start:stop:step including stop, e.g.,
+// #CA3ToCA3:Prjn.Learn.Lrate=0.1,0.2;.EcCa1Prjn:Prjn.Learn.Lrate=0.02:0.05:0.01
+func ParseSweep(spec string) (Sweep, error) {
+	var sw Sweep
+	for _, dst := range strings.Split(spec, ";") {
+		dst = strings.TrimSpace(dst)
+		if dst == "" {
+			continue
+		}
+		eq := strings.Index(dst, "=")
+		cl := strings.Index(dst, ":")
+		if eq < 0 || cl < 0 || cl > eq {
+			return nil, fmt.Errorf("ParseSweep: %s is not of the form Sel:Path=Vals", dst)
+		}
+		sd := SweepDim{Sel: dst[:cl], Path: dst[cl+1 : eq]}
+		vst := dst[eq+1:]
+		if strings.Contains(vst, ":") {
+			vals, err := SweepRange(vst)
+			if err != nil {
+				return nil, fmt.Errorf("ParseSweep: %s: %v", sd.Name(), err)
+			}
+			sd.Vals = vals
+		} else {
+			for _, v := range strings.Split(vst, ",") {
+				if v = strings.TrimSpace(v); v != "" {
+					sd.Vals = append(sd.Vals, v)
+				}
+			}
+		}
+		if len(sd.Vals) == 0 {
+			return nil, fmt.Errorf("ParseSweep: %s has no values", sd.Name())
+		}
+		sw = append(sw, sd)
+	}
+	if len(sw) == 0 {
+		return nil, fmt.Errorf("ParseSweep: no dimensions in: %s", spec)
+	}
+	return sw, nil
+}
+
+// SweepRange returns the values of a range start:stop:step, including stop
+func SweepRange(rng string) ([]string, error) {
+	rs := strings.Split(rng, ":")
+	if len(rs) != 3 {
+		return nil, fmt.Errorf("range %s is not of the form start:stop:step", rng)
+	}
+	var rv [3]float64
+	for i, r := range rs {
+		v, err := strconv.ParseFloat(strings.TrimSpace(r), 64)
+		if err != nil {
+			return nil, err
+		}
+		rv[i] = v
+	}
+	start, stop, step := rv[0], rv[1], rv[2]
+	if step <= 0 || stop < start {
+		return nil, fmt.Errorf("range %s must have start <= stop and step > 0", rng)
+	}
+	n := int(math.Floor((stop-start)/step+1e-9)) + 1
+	vals := make([]string, n)
+	for i := range vals {
+		vals[i] = strconv.FormatFloat(start+float64(i)*step, 'g', 12, 64) // no rounding errors
+	}
+	return vals, nil
+}
+
+// NPoints returns the number of points of the sweep
+func (sw Sweep) NPoints() int {
+	np := 1
+	for i := range sw {
+		np *= len(sw[i].Vals)
+	}
+	return np
+}
+
+// Point returns the values of the dimensions at given point (0 to NPoints-1),
+// with the values of the last dimension varying fastest
+func (sw Sweep) Point(pt int) []string {
+	vals := make([]string, len(sw))
+	for i := len(sw) - 1; i >= 0; i-- {
+		nv := len(sw[i].Vals)
+		vals[i] = sw[i].Vals[pt%nv]
+		pt /= nv
+	}
+	return vals
+}
+
+// ParamsSet returns a params.Set of given name for given point of the sweep: the
+// sheets of the base set if not nil, followed by the swept params at their values
+func (sw Sweep) ParamsSet(name string, base *params.Set, pt int) *params.Set {
+	vals := sw.Point(pt)
+	ps := &params.Set{Name: name, Sheets: params.Sheets{"Network": &params.Sheet{}, "Sim": &params.Sheet{}}}
+	descs := make([]string, len(sw))
+	for i := range sw {
+		descs[i] = sw[i].Name() + "=" + vals[i]
+	}
+	ps.Desc = "sweep point: " + strings.Join(descs, " ")
+	if base != nil {
+		ps.Desc += " -- on top of " + base.Name
+		for snm, sh := range base.Sheets {
+			if psh, ok := ps.Sheets[snm]; ok {
+				*psh = append(*psh, *sh...)
+			}
+		}
+	}
+	for i := range sw {
+		sd := &sw[i]
+		sh := ps.Sheets[sd.Sheet()]
+		*sh = append(*sh, &params.Sel{Sel: sd.Sel, Desc: "sweep", Params: params.Params{sd.Path: vals[i]}})
+	}
+	return ps
+}
+
+// RunSweep runs the runs from StartRun to MaxRuns-1 at each point of the sweep,
+// with a params.Set derived from the current ParamSet, named ParamSet_SweepN for
+// point N, which is added to the Params, replacing those of any previous sweep.
+// The runs of every point have the same seeds, derived from RndSeed, and are done
+// in parallel if Subjects > 1.  The results are in the SweepLog.
+func (ss *Sim) RunSweep(sw Sweep) error {
+	bnm := ss.ParamSet
+	var base *params.Set
+	if bnm != "" && bnm != "Base" {
+		var err error
+		base, err = ss.Params.SetByNameTry(bnm)
+		if err != nil {
+			return err
+		}
+	}
+	pfx := ss.ParamsName() + "_Sweep"
+	sets := params.Sets{} // without the sets of any previous sweep
+	for _, ps := range ss.Params {
+		if !strings.HasPrefix(ps.Name, pfx) {
+			sets = append(sets, ps)
+		}
+	}
+	ss.Params = sets
+	pts := make(map[string]int) // RunLog Params of each point
+	for pt := 0; pt < sw.NPoints(); pt++ {
+		nm := fmt.Sprintf("%s%d", pfx, pt)
+		ps := sw.ParamsSet(nm, base, pt)
+		ss.Params = append(ss.Params, ps)
+		ss.ParamSet = nm
+		fmt.Printf("Sweep point %d of %d: %s\n", pt+1, sw.NPoints(), ps.Desc)
+		ss.Init()
+		pts[ss.RunLogParams()] = pt
+		ss.RunAll()
+	}
+	ss.ParamSet = bnm
+	ss.LogSweep(ss.SweepLog, sw, pts)
+	return nil
+}
+
+// LogSweep sets the SweepLog to the RunLog rows of the points of given sweep,
+// by their RunLog Params, keyed by the values of the swept params, one column per
+// dimension named by Sel:Path
+func (ss *Sim) LogSweep(dt *etable.Table, sw Sweep, pts map[string]int) {
+	rl := ss.RunLog
+	sch := etable.Schema{}
+	for i := range sw {
+		typ := etensor.STRING
+		if sw[i].IsNumeric() {
+			typ = etensor.FLOAT64
+		}
+		sch = append(sch, etable.Column{sw[i].Name(), typ, nil, nil})
+	}
+	sch = append(sch, etable.Column{"Point", etensor.INT64, nil, nil})
+	sch = append(sch, rl.Schema()...)
+	dt.SetFromSchema(sch, 0)
+	dt.SetMetaData("name", "SweepLog")
+	dt.SetMetaData("desc", "Record of performance at end of training of each run of each point of a parameter sweep")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	for rrow := 0; rrow < rl.Rows; rrow++ {
+		pt, ok := pts[rl.CellString("Params", rrow)]
+		if !ok {
+			continue
+		}
+		row := dt.Rows
+		dt.SetNumRows(row + 1)
+		for i, v := range sw.Point(pt) {
+			if fv, err := strconv.ParseFloat(v, 64); err == nil && sch[i].Type == etensor.FLOAT64 {
+				dt.SetCellFloat(sw[i].Name(), row, fv)
+			} else {
+				dt.SetCellString(sw[i].Name(), row, v)
+			}
+		}
+		dt.SetCellFloat("Point", row, float64(pt))
+		for _, cl := range rl.ColNames {
+			dt.CopyCell(cl, row, rl, cl, rrow)
+		}
+	}
+}
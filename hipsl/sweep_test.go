@@ -0,0 +1,65 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSweepRange(t *testing.T) {
+	for _, tc := range []struct {
+		rng  string
+		vals []string
+	}{
+		{"0.02:0.05:0.01", []string{"0.02", "0.03", "0.04", "0.05"}},
+		{"1:2:0.5", []string{"1", "1.5", "2"}},
+		{"0:1:0.3", []string{"0", "0.3", "0.6", "0.9"}},
+		{"3:3:1", []string{"3"}},
+	} {
+		vals, err := SweepRange(tc.rng)
+		if err != nil {
+			t.Errorf("SweepRange(%s): %v", tc.rng, err)
+			continue
+		}
+		if !reflect.DeepEqual(vals, tc.vals) {
+			t.Errorf("SweepRange(%s) = %v, want %v", tc.rng, vals, tc.vals)
+		}
+	}
+	for _, rng := range []string{"1:2", "a:2:1", "2:1:0.5", "1:2:0", "1:2:-1"} {
+		if _, err := SweepRange(rng); err == nil {
+			t.Errorf("SweepRange(%s) did not fail", rng)
+		}
+	}
+}
+
+func TestParseSweep(t *testing.T) {
+	sw, err := ParseSweep("#CA3ToCA3:Prjn.Learn.Lrate=0.1, 0.2;.EcCa1Prjn:Prjn.Learn.Lrate=0.02:0.04:0.01; Sim:Sim.TrialperEpc=120")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Sweep{
+		{Sel: "#CA3ToCA3", Path: "Prjn.Learn.Lrate", Vals: []string{"0.1", "0.2"}},
+		{Sel: ".EcCa1Prjn", Path: "Prjn.Learn.Lrate", Vals: []string{"0.02", "0.03", "0.04"}},
+		{Sel: "Sim", Path: "Sim.TrialperEpc", Vals: []string{"120"}},
+	}
+	if !reflect.DeepEqual(sw, want) {
+		t.Fatalf("got %v, want %v", sw, want)
+	}
+	if sw[2].Sheet() != "Sim" || sw[0].Sheet() != "Network" {
+		t.Errorf("sheets: %s, %s", sw[0].Sheet(), sw[2].Sheet())
+	}
+	if np := sw.NPoints(); np != 6 {
+		t.Errorf("NPoints = %d, want 6", np)
+	}
+	if pt := sw.Point(1); !reflect.DeepEqual(pt, []string{"0.1", "0.03", "120"}) {
+		t.Errorf("Point(1) = %v", pt)
+	}
+	for _, spec := range []string{"", "#CA3ToCA3=0.1", "Prjn.Learn.Lrate=0.1", "#CA3ToCA3:Prjn.Learn.Lrate=", "#CA3ToCA3:Prjn.Learn.Lrate=1:0:1"} {
+		if _, err := ParseSweep(spec); err == nil {
+			t.Errorf("ParseSweep(%q) did not fail", spec)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+// ThetaQtr is the schedule of one quarter of the theta cycle: its length, the
+// strength of the two pathways into CA1 during it, and what happens at its
+// start and end
+type ThetaQtr struct {
+	Cycles     int     `desc:"number of cycles in the quarter -- the minus phase activity ActQ1 (the C++ ActMid) is recorded at the end of Q1, ActM at the end of Q3 and ActP at the end of Q4"`
+	ECinCA1    float32 `desc:"WtScale.Abs of the ECin -> CA1 projection during the quarter"`
+	CA3CA1     float32 `desc:"WtScale.Abs of the CA3 -> CA1 projection during the quarter"`
+	ClampECout bool    `desc:"at the start of the quarter, clamp ECout to the activity of Input, as the target of the EC <-> CA1 auto-encoder -- set at the end of the previous quarter before QuarterFinal, so that it becomes the plus phase input of a Target ECout"`
+	MemStats   bool    `desc:"compute the memory stats (MemStats) at the end of the quarter -- they score ActM so should be at the end of Q3 or later"`
+}
+
+// ThetaMode is the schedule of the four quarters of the theta cycle in one mode
+// (training, testing or replay)
+type ThetaMode struct {
+	ECoutTarget bool     `desc:"ECout is a Target layer, clamped to the ECout pattern in the plus phase -- otherwise a Compare layer, which is not clamped"`
+	Q1          ThetaQtr `view:"inline" desc:"first quarter"`
+	Q2          ThetaQtr `view:"inline" desc:"second quarter"`
+	Q3          ThetaQtr `view:"inline" desc:"third quarter"`
+	Q4          ThetaQtr `view:"inline" desc:"fourth quarter"`
+}
+
+// Qtr returns the schedule of given quarter (0-3)
+func (tm *ThetaMode) Qtr(qtr int) *ThetaQtr {
+	switch qtr {
+	case 0:
+		return &tm.Q1
+	case 1:
+		return &tm.Q2
+	case 2:
+		return &tm.Q3
+	}
+	return &tm.Q4
+}
+
+// Cycles returns the total number of cycles of the four quarters
+func (tm *ThetaMode) Cycles() int {
+	return tm.Q1.Cycles + tm.Q2.Cycles + tm.Q3.Cycles + tm.Q4.Cycles
+}
+
+// ThetaSched is the schedule of the quarters of the theta cycle run by AlphaCyc,
+// for training, testing and replay.  It can be set by the Sim sheet of the param sets,
+// e.g., Sim.Theta.Train.Q1.Cycles, to compare variants such as the timing of the
+// C++ model (see the CppTiming param set).
+type ThetaSched struct {
+	Train  ThetaMode `desc:"schedule of training trials"`
+	Test   ThetaMode `desc:"schedule of testing trials"`
+	Replay ThetaMode `desc:"schedule of the trials of the replay phases (see Replay) -- MemStats are not computed, as there is nothing to recall"`
+}
+
+// Defaults sets the schedule of Ketz et al. (2013), with the quarters of 25 cycles
+// of the Go model: during training, CA1 is driven by ECin in Q1 (the auto-encoder
+// minus phase), by CA3 recall in Q2 and Q3 (the recall minus phase), and by ECin
+// with ECout clamped to Input in Q4 (the plus phase).  During testing, CA1 is
+// driven by both for the whole trial, and ECout is not clamped.  During replay,
+// CA1 is driven by CA3 only, as in the recall quarters of training, so that
+// ECout reflects what CA3 reactivates, and ECout is not clamped.
+func (ts *ThetaSched) Defaults() {
+	trn := &ts.Train
+	trn.ECoutTarget = true
+	trn.Q1 = ThetaQtr{Cycles: 25, ECinCA1: 1, CA3CA1: 0}
+	trn.Q2 = ThetaQtr{Cycles: 25, ECinCA1: 0, CA3CA1: 1}
+	trn.Q3 = ThetaQtr{Cycles: 25, ECinCA1: 0, CA3CA1: 1, MemStats: true}
+	trn.Q4 = ThetaQtr{Cycles: 25, ECinCA1: 3, CA3CA1: 0, ClampECout: true}
+
+	tst := &ts.Test
+	tst.ECoutTarget = false
+	tst.Q1 = ThetaQtr{Cycles: 25, ECinCA1: 3, CA3CA1: 1}
+	tst.Q2 = ThetaQtr{Cycles: 25, ECinCA1: 3, CA3CA1: 1}
+	tst.Q3 = ThetaQtr{Cycles: 25, ECinCA1: 3, CA3CA1: 1, MemStats: true}
+	tst.Q4 = ThetaQtr{Cycles: 25, ECinCA1: 3, CA3CA1: 1}
+
+	rpl := &ts.Replay
+	rpl.ECoutTarget = false
+	rpl.Q1 = ThetaQtr{Cycles: 25, ECinCA1: 0, CA3CA1: 1}
+	rpl.Q2 = ThetaQtr{Cycles: 25, ECinCA1: 0, CA3CA1: 1}
+	rpl.Q3 = ThetaQtr{Cycles: 25, ECinCA1: 0, CA3CA1: 1}
+	rpl.Q4 = ThetaQtr{Cycles: 25, ECinCA1: 0, CA3CA1: 1}
+}
+
+// Mode returns the schedule of training if train, else of testing
+func (ts *ThetaSched) Mode(train bool) *ThetaMode {
+	if train {
+		return &ts.Train
+	}
+	return &ts.Test
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"testing"
+
+	"github.com/emer/leabra/leabra"
+)
+
+// baselineQtr returns the WtScale.Abs of the ECin and CA3 -> CA1 projections
+// during given quarter (0-3), as set by the AlphaCyc of the original model
+func baselineQtr(train bool, qtr int) (ecin, ca3 float32) {
+	if !train {
+		return 3, 1
+	}
+	switch qtr {
+	case 0:
+		return 1, 0
+	case 3:
+		return 3, 0
+	}
+	return 0, 1
+}
+
+func TestThetaDefaults(t *testing.T) {
+	var ts ThetaSched
+	ts.Defaults()
+	var tm leabra.Time
+	tm.Defaults()
+	for _, train := range []bool{true, false} {
+		md := ts.Mode(train)
+		if md.ECoutTarget != train {
+			t.Errorf("train %v: ECoutTarget %v", train, md.ECoutTarget)
+		}
+		if md.Cycles() != 4*tm.CycPerQtr {
+			t.Errorf("train %v: %d cycles, want %d", train, md.Cycles(), 4*tm.CycPerQtr)
+		}
+		for qtr := 0; qtr < 4; qtr++ {
+			qs := md.Qtr(qtr)
+			ecin, ca3 := baselineQtr(train, qtr)
+			if qs.Cycles != tm.CycPerQtr || qs.ECinCA1 != ecin || qs.CA3CA1 != ca3 {
+				t.Errorf("train %v Q%d: %d cycles, ECin %g, CA3 %g, want %d, %g, %g", train, qtr+1, qs.Cycles, qs.ECinCA1, qs.CA3CA1, tm.CycPerQtr, ecin, ca3)
+			}
+			if clamp := train && qtr == 3; qs.ClampECout != clamp {
+				t.Errorf("train %v Q%d: ClampECout %v", train, qtr+1, qs.ClampECout)
+			}
+			if qs.MemStats != (qtr == 2) {
+				t.Errorf("train %v Q%d: MemStats %v", train, qtr+1, qs.MemStats)
+			}
+		}
+	}
+	for qtr := 0; qtr < 4; qtr++ {
+		if qs := ts.Replay.Qtr(qtr); qs.ECinCA1 != 0 || qs.CA3CA1 != 1 || qs.ClampECout || qs.MemStats {
+			t.Errorf("replay Q%d: %+v", qtr+1, *qs)
+		}
+	}
+}
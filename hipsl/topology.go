@@ -0,0 +1,264 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/prjn"
+	"github.com/emer/emergent/relpos"
+	"github.com/emer/leabra/hip"
+	"github.com/emer/leabra/leabra"
+	"github.com/goki/ki/toml"
+)
+
+// TopoLayerNms are the layers every Topology must have, as the Sim refers to them by name
+var TopoLayerNms = []string{"Input", "ECin", "ECout", "DG", "CA3", "CA1"}
+
+// Topology describes the architecture of the hippocampal network built by ConfigNet:
+// its layers and the projections between them.  It can be loaded from a JSON or
+// TOML file to test anatomical variants, e.g., a different size of DG or sparser
+// mossy fibers.  Defaults sets the standard model.
+type Topology struct {
+	Layers []TopoLayer `desc:"layers of the network, in order of construction"`
+	Prjns  []TopoPrjn  `desc:"projections of the network, in order of construction"`
+}
+
+// TopoLayer describes a layer of a Topology
+type TopoLayer struct {
+	Name    string  `desc:"name of the layer"`
+	Type    string  `desc:"type of the layer (emer.LayerType): Input, Hidden, Target or Compare"`
+	Shape   []int   `json:",omitempty" desc:"2D shape of the layer: number of units in Y, X -- ignored if PatSize"`
+	PatSize bool    `json:",omitempty" desc:"if true, the layer has one unit per unit of the patterns (and at least the 12 units of the original model), as a column of units -- for the EC layers"`
+	Class   string  `json:",omitempty" desc:"params class of the layer, e.g., EC"`
+	Rel     string  `json:",omitempty" desc:"position relative to the Other layer in the display (relpos.Relations), e.g., RightOf or Above -- empty for none"`
+	Other   string  `json:",omitempty" desc:"name of the layer that Rel is relative to"`
+	XAlign  string  `json:",omitempty" desc:"horizontal alignment to the Other layer (relpos.XAligns): Left (default), Middle or Right"`
+	YAlign  string  `json:",omitempty" desc:"depth alignment to the Other layer (relpos.YAligns): Front (default), Center or Back"`
+	Space   float32 `json:",omitempty" desc:"space between this and the Other layer in the display"`
+	Thread  int     `json:",omitempty" desc:"thread (goroutine) to update the layer in -- 0 is the main thread"`
+}
+
+// TopoPrjn describes a projection of a Topology
+type TopoPrjn struct {
+	Send    string  `desc:"name of the sending layer"`
+	Recv    string  `desc:"name of the receiving layer"`
+	Pattern string  `desc:"connectivity pattern: Full, OneToOne or UnifRnd"`
+	PCon    float32 `json:",omitempty" desc:"probability of connection for the UnifRnd pattern"`
+	Type    string  `desc:"type of the projection (emer.PrjnType): Forward, Back or Lateral"`
+	Prjn    string  `desc:"hippocampal projection type: EcCa1 (for the EC <-> CA1 pathways) or CHL (for the trisynaptic pathway)"`
+	Class   string  `json:",omitempty" desc:"params class of the projection, e.g., EcCa1Prjn or HippoCHL"`
+}
+
+// Defaults sets the standard model of Schapiro et al. (2017)
+func (tp *Topology) Defaults() {
+	tp.Layers = []TopoLayer{
+		{Name: "Input", Type: "Input", PatSize: true},
+		{Name: "ECin", Type: "Hidden", PatSize: true, Class: "EC", Rel: "RightOf", Other: "Input", Space: 2},
+		{Name: "ECout", Type: "Target", PatSize: true, Class: "EC", Rel: "RightOf", Other: "ECin", Space: 2}, // clamped in plus phase
+		{Name: "CA1", Type: "Hidden", Shape: []int{10, 10}, Rel: "RightOf", Other: "CA3", Space: 2, Thread: 3},
+		{Name: "DG", Type: "Hidden", Shape: []int{20, 20}, Rel: "Above", Other: "ECin", XAlign: "Middle", Thread: 1},
+		{Name: "CA3", Type: "Hidden", Shape: []int{8, 10}, Rel: "Above", Other: "DG", Thread: 2},
+	}
+	tp.Prjns = []TopoPrjn{
+		{Send: "Input", Recv: "ECin", Pattern: "OneToOne", Type: "Forward", Prjn: "EcCa1", Class: "EcCa1Prjn"},
+		{Send: "ECout", Recv: "ECin", Pattern: "OneToOne", Type: "Back", Prjn: "EcCa1", Class: "EcCa1Prjn"},
+		// EC <-> CA1 encoder pathways
+		{Send: "ECin", Recv: "CA1", Pattern: "Full", Type: "Forward", Prjn: "EcCa1", Class: "EcCa1Prjn"},
+		{Send: "CA1", Recv: "ECout", Pattern: "Full", Type: "Forward", Prjn: "EcCa1", Class: "EcCa1Prjn"},
+		{Send: "ECout", Recv: "CA1", Pattern: "Full", Type: "Back", Prjn: "EcCa1", Class: "EcCa1Prjn"},
+		// Perforant pathway
+		{Send: "ECin", Recv: "DG", Pattern: "UnifRnd", PCon: 0.25, Type: "Forward", Prjn: "CHL", Class: "HippoCHL"},
+		{Send: "ECin", Recv: "CA3", Pattern: "UnifRnd", PCon: 0.25, Type: "Forward", Prjn: "CHL", Class: "HippoCHL"},
+		{Send: "CA3", Recv: "CA3", Pattern: "Full", Type: "Lateral", Prjn: "CHL", Class: "HippoCHL"},
+		// Mossy fibers
+		{Send: "DG", Recv: "CA3", Pattern: "UnifRnd", PCon: 0.05, Type: "Forward", Prjn: "CHL", Class: "HippoCHL"},
+		// Schafer collaterals
+		{Send: "CA3", Recv: "CA1", Pattern: "UnifRnd", PCon: 0.25, Type: "Forward", Prjn: "CHL", Class: "HippoCHL"},
+	}
+}
+
+// Open reads the topology from given file: TOML if it has a .toml extension,
+// otherwise JSON -- and validates it
+func (tp *Topology) Open(filename string) error {
+	*tp = Topology{}
+	if filepath.Ext(filename) == ".toml" {
+		if err := toml.Open(tp, filename); err != nil {
+			return err
+		}
+	} else {
+		b, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(b, tp); err != nil {
+			return fmt.Errorf("Topology: %v: %v", filename, err)
+		}
+	}
+	return tp.Validate()
+}
+
+// Save writes the topology to given file: TOML if it has a .toml extension,
+// otherwise JSON
+func (tp *Topology) Save(filename string) error {
+	if filepath.Ext(filename) == ".toml" {
+		return toml.Save(tp, filename)
+	}
+	b, err := json.MarshalIndent(tp, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, b, 0644)
+}
+
+// Layer returns the layer of given name, or nil if not found
+func (tp *Topology) Layer(name string) *TopoLayer {
+	for i := range tp.Layers {
+		if tp.Layers[i].Name == name {
+			return &tp.Layers[i]
+		}
+	}
+	return nil
+}
+
+// Validate returns an error for the first invalid layer or projection found
+func (tp *Topology) Validate() error {
+	for _, lnm := range TopoLayerNms {
+		if tp.Layer(lnm) == nil {
+			return fmt.Errorf("Topology: required layer %s is missing", lnm)
+		}
+	}
+	for li, tl := range tp.Layers {
+		if tl.Name == "" {
+			return fmt.Errorf("Topology: layer %d has no Name", li)
+		}
+		for _, ol := range tp.Layers[:li] {
+			if ol.Name == tl.Name {
+				return fmt.Errorf("Topology: layer %s is defined twice", tl.Name)
+			}
+		}
+		var typ emer.LayerType
+		if err := typ.FromString(tl.Type); err != nil {
+			return fmt.Errorf("Topology: layer %s: %v", tl.Name, err)
+		}
+		if !tl.PatSize && (len(tl.Shape) != 2 || tl.Shape[0] <= 0 || tl.Shape[1] <= 0) {
+			return fmt.Errorf("Topology: layer %s Shape must be 2 positive sizes (Y, X), or PatSize set, is: %v", tl.Name, tl.Shape)
+		}
+		if _, err := tl.RelPos(); err != nil {
+			return fmt.Errorf("Topology: layer %s: %v", tl.Name, err)
+		}
+		if tl.Rel != "" && tp.Layer(tl.Other) == nil {
+			return fmt.Errorf("Topology: layer %s is positioned relative to unknown layer: %s", tl.Name, tl.Other)
+		}
+	}
+	for _, pt := range tp.Prjns {
+		pnm := pt.Send + "To" + pt.Recv
+		if tp.Layer(pt.Send) == nil || tp.Layer(pt.Recv) == nil {
+			return fmt.Errorf("Topology: projection %s has unknown sending or receiving layer", pnm)
+		}
+		if _, err := pt.Pat(); err != nil {
+			return fmt.Errorf("Topology: projection %s: %v", pnm, err)
+		}
+		if pt.NewPrjn() == nil {
+			return fmt.Errorf("Topology: projection %s has unknown Prjn type: %s -- must be EcCa1 or CHL", pnm, pt.Prjn)
+		}
+		var typ emer.PrjnType
+		if err := typ.FromString(pt.Type); err != nil {
+			return fmt.Errorf("Topology: projection %s: %v", pnm, err)
+		}
+		if pt.Pattern == "OneToOne" && tp.Layer(pt.Send).PatSize != tp.Layer(pt.Recv).PatSize {
+			return fmt.Errorf("Topology: OneToOne projection %s must be between layers that are both PatSize", pnm)
+		}
+	}
+	return nil
+}
+
+// RelPos returns the relpos.Rel position of the layer
+func (tl *TopoLayer) RelPos() (relpos.Rel, error) {
+	rp := relpos.Rel{Other: tl.Other, Space: tl.Space}
+	if tl.Rel == "" {
+		return rp, nil
+	}
+	if err := rp.Rel.FromString(tl.Rel); err != nil {
+		return rp, err
+	}
+	if tl.XAlign != "" {
+		if err := rp.XAlign.FromString(tl.XAlign); err != nil {
+			return rp, err
+		}
+	}
+	if tl.YAlign != "" {
+		if err := rp.YAlign.FromString(tl.YAlign); err != nil {
+			return rp, err
+		}
+	}
+	return rp, nil
+}
+
+// Pat returns a new connectivity pattern for the projection
+func (pt *TopoPrjn) Pat() (prjn.Pattern, error) {
+	switch pt.Pattern {
+	case "Full":
+		return prjn.NewFull(), nil
+	case "OneToOne":
+		return prjn.NewOneToOne(), nil
+	case "UnifRnd":
+		if pt.PCon <= 0 || pt.PCon > 1 {
+			return nil, fmt.Errorf("UnifRnd PCon must be in (0, 1], is: %g", pt.PCon)
+		}
+		ur := prjn.NewUnifRnd()
+		ur.PCon = pt.PCon
+		return ur, nil
+	}
+	return nil, fmt.Errorf("unknown Pattern: %s -- must be Full, OneToOne or UnifRnd", pt.Pattern)
+}
+
+// NewPrjn returns a new projection of the hippocampal Prjn type, nil if unknown
+func (pt *TopoPrjn) NewPrjn() emer.Prjn {
+	switch pt.Prjn {
+	case "EcCa1":
+		return &hip.EcCa1Prjn{}
+	case "CHL":
+		return &hip.CHLPrjn{}
+	}
+	return nil
+}
+
+// Config adds the layers and projections to given network, with patSize units
+// in the PatSize layers -- the topology must be valid
+func (tp *Topology) Config(net *leabra.Network, patSize int) {
+	for _, tl := range tp.Layers {
+		var typ emer.LayerType
+		typ.FromString(tl.Type)
+		ny, nx := patSize, 1
+		if !tl.PatSize {
+			ny, nx = tl.Shape[0], tl.Shape[1]
+		}
+		ly := net.AddLayer2D(tl.Name, ny, nx, typ)
+		if tl.Class != "" {
+			ly.SetClass(tl.Class)
+		}
+		if tl.Rel != "" {
+			rp, _ := tl.RelPos()
+			ly.SetRelPos(rp)
+		}
+		if tl.Thread > 0 {
+			ly.(leabra.LeabraLayer).SetThread(tl.Thread)
+		}
+	}
+	for _, pt := range tp.Prjns {
+		var typ emer.PrjnType
+		typ.FromString(pt.Type)
+		pat, _ := pt.Pat()
+		pj := net.ConnectLayersPrjn(net.LayerByName(pt.Send), net.LayerByName(pt.Recv), pat, typ, pt.NewPrjn())
+		if pt.Class != "" {
+			pj.SetClass(pt.Class)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTopologyValidate(t *testing.T) {
+	var tp Topology
+	tp.Defaults()
+	if err := tp.Validate(); err != nil {
+		t.Fatalf("Defaults: %v", err)
+	}
+
+	bad := []struct {
+		nm   string
+		edit func(tp *Topology)
+		err  string
+	}{
+		{"missing layer", func(tp *Topology) { tp.Layers = tp.Layers[1:] }, "required layer Input is missing"},
+		{"no name", func(tp *Topology) { tp.Layers = append(tp.Layers, TopoLayer{Type: "Hidden", Shape: []int{2, 2}}) }, "has no Name"},
+		{"twice", func(tp *Topology) { tp.Layers = append(tp.Layers, tp.Layers[4]) }, "layer DG is defined twice"},
+		{"layer type", func(tp *Topology) { tp.Layers[4].Type = "Hiden" }, "layer DG"},
+		{"shape", func(tp *Topology) { tp.Layers[4].Shape = []int{20} }, "layer DG Shape"},
+		{"zero shape", func(tp *Topology) { tp.Layers[4].Shape = []int{0, 20} }, "layer DG Shape"},
+		{"rel", func(tp *Topology) { tp.Layers[4].Rel = "Beside" }, "layer DG"},
+		{"other", func(tp *Topology) { tp.Layers[4].Other = "EC" }, "relative to unknown layer: EC"},
+		{"prjn layer", func(tp *Topology) { tp.Prjns[5].Recv = "DG2" }, "projection ECinToDG2 has unknown"},
+		{"pattern", func(tp *Topology) { tp.Prjns[5].Pattern = "Rnd" }, "projection ECinToDG: unknown Pattern"},
+		{"pcon", func(tp *Topology) { tp.Prjns[5].PCon = 0 }, "projection ECinToDG: UnifRnd PCon"},
+		{"prjn", func(tp *Topology) { tp.Prjns[5].Prjn = "Hebb" }, "unknown Prjn type: Hebb"},
+		{"prjn type", func(tp *Topology) { tp.Prjns[5].Type = "Fwd" }, "projection ECinToDG"},
+		{"one to one", func(tp *Topology) { tp.Prjns[0].Recv = "DG" }, "OneToOne projection InputToDG"},
+	}
+	for _, bt := range bad {
+		tp.Defaults()
+		bt.edit(&tp)
+		err := tp.Validate()
+		if err == nil {
+			t.Errorf("%s: no error", bt.nm)
+		} else if !strings.Contains(err.Error(), bt.err) {
+			t.Errorf("%s: error %q does not contain %q", bt.nm, err, bt.err)
+		}
+	}
+}
+
+func TestTopologyOpen(t *testing.T) {
+	var tp Topology
+	tp.Defaults()
+	tp.Layers[4].Shape = []int{10, 10}
+	tp.Prjns[8].PCon = 0.1
+	for _, ext := range []string{".json", ".toml"} {
+		fn := filepath.Join(t.TempDir(), "topo"+ext)
+		if err := tp.Save(fn); err != nil {
+			t.Fatal(err)
+		}
+		var otp Topology
+		if err := otp.Open(fn); err != nil {
+			t.Fatalf("%s: %v", ext, err)
+		}
+		if dg := otp.Layer("DG"); dg == nil || len(dg.Shape) != 2 || dg.Shape[0] != 10 || dg.Shape[1] != 10 {
+			t.Errorf("%s: DG: %v", ext, dg)
+		}
+		if len(otp.Prjns) != len(tp.Prjns) || otp.Prjns[8] != tp.Prjns[8] {
+			t.Errorf("%s: Prjns: %v", ext, otp.Prjns)
+		}
+	}
+
+	tp.Layers = tp.Layers[1:]
+	fn := filepath.Join(t.TempDir(), "topo.json")
+	if err := tp.Save(fn); err != nil {
+		t.Fatal(err)
+	}
+	var otp Topology
+	if err := otp.Open(fn); err == nil {
+		t.Errorf("Open of an invalid topology: no error")
+	}
+}
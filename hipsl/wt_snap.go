@@ -0,0 +1,297 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/leabra/leabra"
+)
+
+// WtSnap takes a snapshot of the weights of the Prjns at the end of each training
+// epoch, from which LogWtEpc logs in the WtEpcLog how much they changed since the
+// previous one, their distribution, and the effective connectivity of each
+// projection between the representations of the test items of the same group
+// (e.g., pair) vs. of different groups, to see where the structure is stored over
+// training.  If Save, the snapshots are also saved to NumPy .npz files.
+type WtSnap struct {
+	On    bool                 `desc:"take a snapshot of the weights after each training epoch, and log its stats in the WtEpcLog"`
+	Prjns []string             `desc:"names of the projections to take snapshots of -- those not in the network are ignored"`
+	NBins int                  `desc:"number of bins of the histograms of the weights, over 0-1"`
+	Save  bool                 `desc:"save each snapshot to a NumPy .npz file per run and epoch in Dir, with the recv x send weights of the Prjns"`
+	Dir   string               `desc:"directory to write the .npz files of the snapshots to -- created if it does not exist"`
+	Prev  map[string][]float32 `json:"-" view:"-" desc:"weights of each projection at the previous snapshot, in the order of its synapses"`
+}
+
+// Defaults takes snapshots of the projections to and within CA3 and CA1 that
+// learn the pairs: CA3ToCA3, ECinToCA3, CA3ToCA1 and ECinToCA1
+func (ws *WtSnap) Defaults() {
+	ws.Prjns = []string{"CA3ToCA3", "ECinToCA3", "CA3ToCA1", "ECinToCA1"}
+	ws.NBins = 10
+	ws.Dir = "wts"
+}
+
+// Snap records the current weights of given projections as the previous snapshot
+func (ws *WtSnap) Snap(pjs []*leabra.Prjn) {
+	ws.Prev = make(map[string][]float32)
+	for _, pj := range pjs {
+		wts := make([]float32, len(pj.Syns))
+		for i := range pj.Syns {
+			wts[i] = pj.Syns[i].Wt
+		}
+		ws.Prev[pj.Name()] = wts
+	}
+}
+
+// MeanAbsDWt returns the mean absolute change of the weights of given projection
+// since the previous snapshot -- NaN if none
+func (ws *WtSnap) MeanAbsDWt(pj *leabra.Prjn) float64 {
+	prv := ws.Prev[pj.Name()]
+	if len(prv) != len(pj.Syns) || len(prv) == 0 {
+		return math.NaN()
+	}
+	sum := 0.0
+	for i := range pj.Syns {
+		sum += math.Abs(float64(pj.Syns[i].Wt - prv[i]))
+	}
+	return sum / float64(len(prv))
+}
+
+// Hist returns the mean of the weights of given projection and their histogram:
+// the proportion of them in each of the NBins bins over 0-1
+func (ws *WtSnap) Hist(pj *leabra.Prjn) (float64, *etensor.Float64) {
+	hist := etensor.NewFloat64([]int{ws.NBins}, nil, []string{"Bin"})
+	if len(pj.Syns) == 0 {
+		return math.NaN(), hist
+	}
+	sum := 0.0
+	for i := range pj.Syns {
+		wt := float64(pj.Syns[i].Wt)
+		sum += wt
+		bin := int(wt * float64(ws.NBins))
+		if bin < 0 {
+			bin = 0
+		} else if bin >= ws.NBins {
+			bin = ws.NBins - 1
+		}
+		hist.Values[bin]++
+	}
+	n := float64(len(pj.Syns))
+	for i := range hist.Values {
+		hist.Values[i] /= n
+	}
+	return sum / n, hist
+}
+
+// WtSnapPrjns returns the projections of the WtSnap Prjns that are in the network
+func (ss *Sim) WtSnapPrjns() []*leabra.Prjn {
+	var pjs []*leabra.Prjn
+	for _, pnm := range ss.WtSnap.Prjns {
+		for _, ly := range ss.Net.Layers {
+			for _, p := range ly.(leabra.LeabraLayer).AsLeabra().RcvPrjns {
+				if p.Name() == pnm {
+					pjs = append(pjs, p.(leabra.LeabraPrjn).AsLeabra())
+				}
+			}
+		}
+	}
+	return pjs
+}
+
+// EffConn returns the effective connectivity of given projection between the
+// representations of the test items of the same RSA group (within) and of
+// different groups (across), from their patterns in the RSA of the last TestAll
+// (see LogWtEpc for when they are not of the current epoch):
+// for each ordered pair of different items, the mean weight of the synapses from
+// the units of the first item in the sending layer to the units of the second in
+// the receiving layer, weighted by their activity.  They are NaN if the layers
+// are not among the RSA Layers.
+func (ss *Sim) EffConn(pj *leabra.Prjn) (within, across float64) {
+	pats := ss.RSA.Pats
+	scl, err := pats.ColByNameTry(pj.Send.Name())
+	if err != nil {
+		return math.NaN(), math.NaN()
+	}
+	rcl, err := pats.ColByNameTry(pj.Recv.Name())
+	if err != nil {
+		return math.NaN(), math.NaN()
+	}
+	wts, mask := PrjnWts(pj)
+	nr, ns := wts.Dim(0), wts.Dim(1)
+	n := pats.Rows
+	// summed weights and synapses from the sending pattern of each item, per recv unit
+	wsum := make([][]float64, n)
+	msum := make([][]float64, n)
+	for i := 0; i < n; i++ {
+		wsum[i] = make([]float64, nr)
+		msum[i] = make([]float64, nr)
+		for ri := 0; ri < nr; ri++ {
+			for si := 0; si < ns; si++ {
+				if !mask.Value1D(ri*ns + si) {
+					continue
+				}
+				act := scl.FloatValRowCell(i, si)
+				wsum[i][ri] += act * float64(wts.Values[ri*ns+si])
+				msum[i][ri] += act
+			}
+		}
+	}
+	nw, na := 0, 0
+	for i := 0; i < n; i++ {
+		gi := pats.CellString("Group", i)
+		for j := 0; j < n; j++ {
+			if j == i {
+				continue
+			}
+			ws, ms := 0.0, 0.0
+			for ri := 0; ri < nr; ri++ {
+				act := rcl.FloatValRowCell(j, ri)
+				ws += act * wsum[i][ri]
+				ms += act * msum[i][ri]
+			}
+			if ms == 0 {
+				continue
+			}
+			if gi == pats.CellString("Group", j) {
+				within += ws / ms
+				nw++
+			} else {
+				across += ws / ms
+				na++
+			}
+		}
+	}
+	if nw > 0 {
+		within /= float64(nw)
+	} else {
+		within = math.NaN()
+	}
+	if na > 0 {
+		across /= float64(na)
+	} else {
+		across = math.NaN()
+	}
+	return
+}
+
+// WtSnapFileName returns the name of the .npz file of the weight snapshot of
+// given run and epoch, in the WtSnap Dir
+func (ss *Sim) WtSnapFileName(run, epc int) string {
+	return ss.Net.Nm + "_" + ss.RunName() + fmt.Sprintf("_wts_run%d_epc%d.npz", run, epc)
+}
+
+// SaveWtSnap saves the weights of given projections to a NumPy .npz file of given
+// name, as the recv x send arrays named after them, with their masks, as by
+// NpzFile AddPrjnWts, and the prjns, run and epoch arrays
+func (ss *Sim) SaveWtSnap(fname string, pjs []*leabra.Prjn) error {
+	if dir := filepath.Dir(fname); dir != "." {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return err
+		}
+	}
+	nz, err := CreateNpz(fname)
+	if err != nil {
+		return err
+	}
+	trn := ss.TrainEnv.Ctrs()
+	var pnms []string
+	for _, pj := range pjs {
+		wts, mask := PrjnWts(pj)
+		if err = nz.Add(pj.Name(), wts); err != nil {
+			break
+		}
+		if err = nz.Add(pj.Name()+"_mask", mask); err != nil {
+			break
+		}
+		pnms = append(pnms, pj.Name())
+	}
+	if err == nil {
+		err = nz.AddStrings("prjns", pnms)
+	}
+	if err == nil {
+		err = nz.AddInts("run", []int{trn.Run.Cur})
+	}
+	if err == nil {
+		err = nz.AddInts("epoch", []int{trn.Epoch.Prv})
+	}
+	if cerr := nz.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+//////////////////////////////////////////////
+//  WtEpcLog
+
+// LogWtEpc adds the stats of the weights of the WtSnap Prjns at the end of the
+// current training epoch to the WtEpcLog, and takes their snapshot, saved if
+// Save: for each projection, the mean absolute change of its weights since the
+// previous snapshot (DWt), their mean (Wt) and histogram (Hist), and its
+// effective connectivity between the items of the same group (Within) and of
+// different groups (Across), see EffConn -- NaN if the items were not tested
+// after this epoch (e.g., TestInterval > 1), as their RSA patterns are older.
+func (ss *Sim) LogWtEpc(dt *etable.Table) {
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	trn := ss.TrainEnv.Ctrs()
+	epc := trn.Epoch.Prv // this is triggered by increment so use previous value
+	dt.SetCellFloat("Run", row, float64(trn.Run.Cur))
+	dt.SetCellFloat("Epoch", row, float64(epc))
+	tested := ss.RSA.Run == trn.Run.Cur && ss.RSA.Epoch == epc
+	pjs := ss.WtSnapPrjns()
+	for _, pj := range pjs {
+		pnm := pj.Name()
+		dt.SetCellFloat(pnm+" DWt", row, ss.WtSnap.MeanAbsDWt(pj))
+		mean, hist := ss.WtSnap.Hist(pj)
+		dt.SetCellFloat(pnm+" Wt", row, mean)
+		dt.SetCellTensor(pnm+" Hist", row, hist)
+		within, across := math.NaN(), math.NaN()
+		if tested {
+			within, across = ss.EffConn(pj)
+		}
+		dt.SetCellFloat(pnm+" Within", row, within)
+		dt.SetCellFloat(pnm+" Across", row, across)
+	}
+	dt.SetCellString("Manifest", row, ss.ManifestRef())
+	ss.WtSnap.Snap(pjs)
+	if ss.WtSnap.Save {
+		fnm := filepath.Join(ss.WtSnap.Dir, ss.WtSnapFileName(trn.Run.Cur, epc))
+		if err := ss.SaveWtSnap(fnm, pjs); err != nil {
+			log.Println(err)
+		}
+	}
+
+	ss.LogUpdated(dt, row)
+}
+
+func (ss *Sim) ConfigWtEpcLog(dt *etable.Table) {
+	dt.SetMetaData("name", "WtEpcLog")
+	dt.SetMetaData("desc", "Change, distribution and effective connectivity of the weights of projections over epochs of training")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+	}
+	for _, pj := range ss.WtSnapPrjns() {
+		pnm := pj.Name()
+		sch = append(sch, etable.Column{pnm + " DWt", etensor.FLOAT64, nil, nil})
+		sch = append(sch, etable.Column{pnm + " Wt", etensor.FLOAT64, nil, nil})
+		sch = append(sch, etable.Column{pnm + " Within", etensor.FLOAT64, nil, nil})
+		sch = append(sch, etable.Column{pnm + " Across", etensor.FLOAT64, nil, nil})
+		sch = append(sch, etable.Column{pnm + " Hist", etensor.FLOAT64, []int{ss.WtSnap.NBins}, []string{"Bin"}})
+	}
+	sch = append(sch, etable.Column{"Manifest", etensor.STRING, nil, nil})
+	dt.SetFromSchema(sch, 0)
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hipsl
+
+import (
+	"math"
+	"testing"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/leabra/leabra"
+)
+
+// newWtSnapSim returns a new Sim of the default config, and its projection of
+// given name
+func newWtSnapSim(t *testing.T, pnm string) (*Sim, *leabra.Prjn) {
+	t.Helper()
+	chdirRoot(t)
+	ss, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss.WtSnap.Prjns = []string{pnm, "NoneToCA1"}
+	pjs := ss.WtSnapPrjns()
+	if len(pjs) != 1 || pjs[0].Name() != pnm {
+		t.Fatalf("WtSnapPrjns: %v", pjs)
+	}
+	return ss, pjs[0]
+}
+
+func TestWtSnapStats(t *testing.T) {
+	ss, pj := newWtSnapSim(t, "CA3ToCA1")
+	ws := &ss.WtSnap
+	ws.Prev = nil
+	if d := ws.MeanAbsDWt(pj); !math.IsNaN(d) {
+		t.Errorf("MeanAbsDWt with no snapshot: %g", d)
+	}
+	ws.Snap([]*leabra.Prjn{pj})
+	if d := ws.MeanAbsDWt(pj); d != 0 {
+		t.Errorf("MeanAbsDWt with no change: %g", d)
+	}
+	n := len(pj.Syns)
+	pj.Syns[0].Wt += 0.25
+	pj.Syns[1].Wt -= 0.25
+	pj.Syns[2].Wt += 0.5
+	if d, want := ws.MeanAbsDWt(pj), 1.0/float64(n); math.Abs(d-want) > 1e-6 {
+		t.Errorf("MeanAbsDWt: %g, want %g", d, want)
+	}
+
+	// a quarter of the weights at 0.05 (bin 0), half at 0.55 (bin 5), and the
+	// rest at 1, which is in the last bin
+	for i := range pj.Syns {
+		switch {
+		case i < n/4:
+			pj.Syns[i].Wt = 0.05
+		case i < 3*n/4:
+			pj.Syns[i].Wt = 0.55
+		default:
+			pj.Syns[i].Wt = 1
+		}
+	}
+	mean, hist := ws.Hist(pj)
+	nb0, nb5 := float64(n/4), float64(3*n/4-n/4)
+	nb9 := float64(n) - nb0 - nb5
+	if want := (0.05*nb0 + 0.55*nb5 + nb9) / float64(n); math.Abs(mean-want) > 1e-6 {
+		t.Errorf("Hist mean: %g, want %g", mean, want)
+	}
+	if hist.Len() != ws.NBins {
+		t.Fatalf("Hist: %d bins, want %d", hist.Len(), ws.NBins)
+	}
+	for bin, v := range hist.Values {
+		want := 0.0
+		switch bin {
+		case 0:
+			want = nb0 / float64(n)
+		case 5:
+			want = nb5 / float64(n)
+		case 9:
+			want = nb9 / float64(n)
+		}
+		if math.Abs(v-want) > 1e-9 {
+			t.Errorf("Hist bin %d: %g, want %g", bin, v, want)
+		}
+	}
+}
+
+func TestEffConn(t *testing.T) {
+	ss, pj := newWtSnapSim(t, "ECinToCA1")
+	ecin := ss.Net.LayerByName("ECin").Shape()
+	ca1 := ss.Net.LayerByName("CA1").Shape()
+	// items A and B of one pair and C of another, each a single unit in ECin and CA1
+	pats := &etable.Table{}
+	pats.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Group", etensor.STRING, nil, nil},
+		{"ECin", etensor.FLOAT32, ecin.Shp, nil},
+		{"CA1", etensor.FLOAT32, ca1.Shp, nil},
+	}, 3)
+	groups := []string{"AB", "AB", "C"}
+	for i, nm := range []string{"A", "B", "C"} {
+		pats.SetCellString("Name", i, nm)
+		pats.SetCellString("Group", i, groups[i])
+		pats.CellTensor("ECin", i).SetFloat1D(i, 1)
+		pats.CellTensor("CA1", i).SetFloat1D(i, 1)
+	}
+	ss.RSA.Pats = pats
+
+	for ri := 0; ri < ca1.Len(); ri++ {
+		for si := 0; si < ecin.Len(); si++ {
+			pj.SetSynVal("Wt", si, ri, 0.2)
+		}
+	}
+	pj.SetSynVal("Wt", 0, 1, 0.9) // A -> B
+	pj.SetSynVal("Wt", 1, 0, 0.7) // B -> A
+	within, across := ss.EffConn(pj)
+	if math.Abs(within-0.8) > 1e-6 || math.Abs(across-0.2) > 1e-6 {
+		t.Errorf("EffConn: within %g, across %g, want 0.8, 0.2", within, across)
+	}
+
+	ca3ca1 := ss.Net.LayerByName("CA1").(leabra.LeabraLayer).AsLeabra().SendName("CA3").(leabra.LeabraPrjn).AsLeabra()
+	if within, across := ss.EffConn(ca3ca1); !math.IsNaN(within) || !math.IsNaN(across) {
+		t.Errorf("EffConn of a layer not in the RSA: %g, %g", within, across)
+	}
+	pats.SetCellString("Group", 2, "AB")
+	if _, across := ss.EffConn(pj); !math.IsNaN(across) {
+		t.Errorf("EffConn across with a single group: %g", across)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/schapirolab/hip-sl/hipsl"
+)
+
+// Npy is the npy subcommand, which exports the weights of the weights files saved
+// with -wts, and the activity of each test item tested with them, to NumPy .npz
+// files, as given by the args after npy, e.g.:
+//
+//	hip-sl npy Hip_Base_run0.wts.gz
+//
+// The network is that of the -paradigm and -topo args.  It returns the exit code
+// of the program.
+func Npy(args []string) int {
+	cfg := hipsl.DefaultConfig()
+	fs := flag.NewFlagSet("npy", flag.ExitOnError)
+	var out, lays, paradigm string
+	fs.StringVar(&out, "o", "", "file to save the .npz to, for one weights file only -- by default its name with .npz instead of .wts[.gz], e.g., Hip_Base_run0.npz")
+	fs.StringVar(&lays, "layers", strings.Join(cfg.NpyRec.Layers, ","), "names of the layers to export the activity of, separated by commas")
+	fs.StringVar(&cfg.NpyRec.Var, "var", cfg.NpyRec.Var, "unit variable of the activity to export, e.g., Act or Ge")
+	fs.BoolVar(&cfg.NpyRec.Wts, "wts", cfg.NpyRec.Wts, "if true, export the weight matrices of the projections")
+	fs.StringVar(&paradigm, "paradigm", cfg.Paradigm.String(), "statistical learning paradigm of the network and test items: Pairs, Community or AssocInf")
+	fs.StringVar(&cfg.Topology, "topo", "", "JSON or TOML file of the topology of the network, instead of the default")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s npy [flags] files.wts[.gz]...\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 || (out != "" && fs.NArg() > 1) {
+		fs.Usage()
+		return 2
+	}
+	if err := cfg.Paradigm.FromString(paradigm); err != nil {
+		log.Println(err)
+		return 2
+	}
+	cfg.NpyRec.Layers = nil
+	for _, lnm := range strings.Split(lays, ",") {
+		if lnm = strings.TrimSpace(lnm); lnm != "" {
+			cfg.NpyRec.Layers = append(cfg.NpyRec.Layers, lnm)
+		}
+	}
+	cfg.NpyRec.On = true
+	cfg.NpyRec.Save = false // saved to the file of each weights file
+	ss, err := hipsl.New(cfg)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	code := 0
+	for _, fnm := range fs.Args() {
+		onm := out
+		if onm == "" {
+			onm = strings.TrimSuffix(strings.TrimSuffix(fnm, ".gz"), ".wts") + ".npz"
+		}
+		if err := ss.Net.OpenWtsJSON(gi.FileName(fnm)); err != nil {
+			log.Println(err)
+			code = 1
+			continue
+		}
+		ss.TestAll()
+		if err := ss.SaveNpz(onm); err != nil {
+			log.Println(err)
+			code = 1
+			continue
+		}
+		fmt.Printf("%s: %d test items -> %s\n", fnm, len(ss.NpyRec.Items), onm)
+	}
+	return code
+}